@@ -6,29 +6,121 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
+	"text/template"
 
 	"gopkg.in/yaml.v3"
 )
 
 // PluginsCfg is a configuration for the plugin.
 type PluginsCfg struct {
-	Splitter *SplitterConfig `yaml:"types_splitter"`
+	// Version is the version of the config format, older versions are migrated when read.
+	Version  int             `yaml:"version" desc:"Version of the config format, configs without version are version 1."`
+	Splitter *SplitterConfig `yaml:"types_splitter" required:"true" desc:"Configuration of the types_splitter gqlgen plugin."`
 }
 
 // SplitterConfig is a configuration for splitting queries, mutations, subscriptions and types into multiple files.
 type SplitterConfig struct {
-	QueryConfig QuerySplitConfigs `yaml:"queries"`
-	TypeConfig  TypeSplitConfigs  `yaml:"types"`
+	QueryConfig QuerySplitConfigs `yaml:"queries" desc:"Queries, mutations and subscriptions to split, the first matching config is used."`
+	TypeConfig  TypeSplitConfigs  `yaml:"types" desc:"Object types to split, the first matching config is used."`
+
+	// Strict rejects rules that can't have any effect on the schema instead of warning about them.
+	Strict bool `yaml:"strict" desc:"Rejects rules that can't have any effect on the schema instead of warning about them."`
+
+	// Expect is a map of root fields (eg. getUser or Query.getUser) and types to the prefix they are expected
+	// to be split into. An empty prefix means that they are expected to stay in their original source.
+	Expect map[string]string `yaml:"expect" desc:"Expected prefix of root fields (eg. getUser or Query.getUser) and types, an empty prefix meaning not split."`
+
+	// Lock is the path of the lock file recording the placement of every root field and type, relative to the config file.
+	// Locked placements are kept across runs, only new fields and types are placed by the rules.
+	Lock string `yaml:"lock" desc:"Path of the lock file recording the placement of every root field and type, relative to the config file."`
+
+	// WriteSources writes the split sources to disk: new sources are created, shrunken original sources
+	// are rewritten, and emptied original sources are deleted.
+	WriteSources bool `yaml:"write_sources" desc:"Writes the split sources to disk, rewriting and deleting the original sources accordingly."`
+
+	// Manifest is the path of the manifest describing every moved definition and field, relative to the config file.
+	// The format is JSON, or YAML if the path ends with .yml or .yaml.
+	Manifest string `yaml:"manifest" desc:"Path of the JSON (or YAML, with a .yml or .yaml extension) manifest of the split, relative to the config file."`
+
+	// Header adds a header comment to new sources naming the plugin, the prefix, the rules and the original
+	// sources they were produced from.
+	Header bool `yaml:"header" desc:"Adds a header comment to new sources naming the plugin, the prefix, the rules and the original sources."`
+
+	// Format is how new sources are printed: with their template (default), or canonically with the gqlparser formatter.
+	Format string `yaml:"format" enum:"template,canonical" desc:"How new sources are printed: with their template (default), or canonically with the gqlparser formatter."`
+
+	// Order is the order of the fields and types in new sources: by original source and position (default),
+	// alphabetical, or in the order of the matching rules.
+	Order string `yaml:"order" enum:"original,alphabetical,config" desc:"Order of the fields and types in new sources: by original source and position (default), alphabetical, or in the order of the matching rules."`
+
+	// Layout is where new sources are created: next to their original source (default), or in a directory per prefix.
+	Layout string `yaml:"layout" enum:"flat,domain" desc:"Where new sources are created: next to their original source (default), or in a directory per prefix, dotted prefixes being nested directories."`
+
+	// Mode is what the split applies to: the schema sources and their resolvers (default), or the resolver files only,
+	// the sources embedded in the generated code being left as they were loaded.
+	Mode string `yaml:"mode" enum:"schema,resolvers" desc:"What the split applies to: the schema sources and their resolvers (default), or the resolver files only, the sources embedded in the generated code being left as they were loaded."`
+
+	// FilenameTemplates are the templates of the names of new sources for each kind, relative to the directory
+	// of the original source.
+	FilenameTemplates FilenameTemplatesConfig `yaml:"filename_template" desc:"Templates of the names of new sources for each kind, relative to the directory of the original source."`
+
+	// ResolverSuffix is the suffix of the resolver files generated with the follow-schema layout, eg. users.queries.resolvers.go,
+	// unless the resolver config of gqlgen has a filename_template.
+	ResolverSuffix string `yaml:"resolver_suffix" desc:"Suffix of the resolver files generated with the follow-schema layout, .resolvers by default, unless the resolver config of gqlgen has a filename_template."`
+
+	// ResolverLayout forces the layout of the resolver config of gqlgen, as the plugin did before version 3 of the
+	// config format. Configs of older versions are migrated with follow-schema so that their resolvers are still split.
+	ResolverLayout string `yaml:"resolver_layout" enum:"follow-schema" desc:"Forces the layout of the resolver config of gqlgen, in the directory of its filename when it's set. Configs of older versions are migrated with follow-schema."`
+
+	// OrphanResolvers is what happens to the resolver files of the follow-schema layout that no source maps to
+	// anymore after generation: they are reported as warnings (default), or deleted.
+	OrphanResolvers string `yaml:"orphan_resolvers" enum:"report,delete" desc:"What happens to the resolver files that no source maps to anymore after generation: they are reported as warnings (default), or deleted."`
+
+	// KeepResolvers are glob patterns of resolver files, relative to the resolver dir, that are never reported
+	// nor deleted as orphans, eg. hand-written files.
+	KeepResolvers []string `yaml:"keep_resolvers" desc:"Glob patterns of resolver files, relative to the resolver dir, never reported nor deleted as orphans."`
+
+	// MoveResolvers moves the implementations of the resolvers generated in another file than before, with the
+	// imports and the helper functions they use, so that changing the split never loses code.
+	MoveResolvers bool `yaml:"move_resolvers" desc:"Moves the implementations of the resolvers generated in another file than before, with the imports and helper functions they use."`
+
+	// ResolverPackages generates the resolvers of each prefix in its own package under the resolver dir, the
+	// resolvers of the resolver package delegating to them.
+	ResolverPackages bool `yaml:"resolver_packages" desc:"Generates the resolvers of each prefix in its own package under the resolver dir, the resolvers of the resolver package delegating to them."`
+
+	// SplitModels moves the models of the split types from the models file to a file per prefix next to it,
+	// eg. users.models_gen.go. Input types follow the prefix of the fields using them, if they're all of the same prefix.
+	SplitModels bool `yaml:"split_models" desc:"Moves the models of the split types, and of the input types only used by fields of the same prefix, from the models file to a file per prefix next to it."`
+
+	// ModelPackages moves the models of the split types from the models file to a package per prefix under the model
+	// dir, eg. graph/model/users, and binds the types to it.
+	ModelPackages bool `yaml:"model_packages" desc:"Moves the models of the split types from the models file to a package per prefix under the model dir, binding the types to it."`
+
+	// SplitExec moves the exec code of the split root fields to the file of their source when SplitExec is called after
+	// api.Generate. It requires the follow-schema exec layout of gqlgen, which generates the exec code of each source
+	// in its own file.
+	SplitExec bool `yaml:"split_exec" desc:"Moves the exec code of the split root fields to the file of their source when SplitExec is called after api.Generate, requires the follow-schema exec layout."`
+
+	// ResolverTests generates a test file next to the resolvers file of each new source, with a table-driven test per
+	// resolver method of the fields moved to the source. Existing test files are never overwritten.
+	ResolverTests bool `yaml:"resolver_tests" desc:"Generates a test file next to the resolvers file of each new source, with a table-driven test per resolver method, never overwriting existing test files."`
+
+	// Templates are the paths of custom templates used to generate new sources, relative to the config file.
+	Templates TemplatesConfig `yaml:"templates" desc:"Paths of custom templates used to generate new sources, relative to the config file."`
+
+	// dir is the directory of the config file, used to resolve relative paths
+	dir string
 }
 
 // QuerySplitConfig is a configuration for splitting queries and mutations into multiple files.
 type QuerySplitConfig struct {
 	// ResolverPrefix is the prefix that will be added to the resolver file name eg. racing => racing.queries.go.
-	ResolverPrefix string `yaml:"prefix"`
+	ResolverPrefix string `yaml:"prefix" required:"true" validate:"prefix" desc:"Prefix of the generated files, eg. users => users.queries.graphql."`
 	// Matches is a list of string regexes that will be used to match against the query name. They must be ordered by priority.
-	Matches []string `yaml:"matches"`
+	Matches []string `yaml:"matches" required:"true" validate:"regex" desc:"Case insensitive regexes matched against the query, mutation and subscription names."`
 	// matches is a list of compiled regexes that will be used to match against the query name.
 	matches []*regexp.Regexp
 }
@@ -38,13 +130,39 @@ type QuerySplitConfigs []QuerySplitConfig
 // TypeSplitConfig is a configuration for splitting types into multiple files.
 type TypeSplitConfig struct {
 	// Name is the name of the type that will be used to match against the type name. eg. RacingRace
-	Name string `yaml:"name"`
+	Name string `yaml:"name" required:"true" desc:"Name of the object type to split, eg. User."`
 	// ResolverPrefix is the prefix that will be added to the resolver file name eg. racing_race => racing_race.resolvers.go.
-	ResolverPrefix string `yaml:"prefix"`
+	ResolverPrefix string `yaml:"prefix" required:"true" validate:"prefix" desc:"Prefix of the generated file, eg. users => users.graphql."`
 }
 
 type TypeSplitConfigs []TypeSplitConfig
 
+// FilenameTemplatesConfig is a configuration of the names of new sources. Templates may use the placeholders
+// {prefix}, {prefix_dir} (the prefix with dots replaced by /), {kind} (query, mutation, subscription or type),
+// {original} (the name of the original source without extension) and {ext} (the extension of the original source),
+// and / to create directories. Templates that aren't set use the default ones of the layout.
+type FilenameTemplatesConfig struct {
+	// Query is the template of sources of Query fields, {prefix}.{original}{ext} by default.
+	Query string `yaml:"query" validate:"filename_template" desc:"Template of the names of sources of Query fields, {prefix}.{original}{ext} by default."`
+	// Mutation is the template of sources of Mutation fields, {prefix}.{original}{ext} by default.
+	Mutation string `yaml:"mutation" validate:"filename_template" desc:"Template of the names of sources of Mutation fields, {prefix}.{original}{ext} by default."`
+	// Subscription is the template of sources of Subscription fields, {prefix}.{original}{ext} by default.
+	Subscription string `yaml:"subscription" validate:"filename_template" desc:"Template of the names of sources of Subscription fields, {prefix}.{original}{ext} by default."`
+	// Type is the template of sources of object types, {prefix}{ext} by default.
+	Type string `yaml:"type" validate:"filename_template" desc:"Template of the names of sources of object types, {prefix}{ext} by default."`
+}
+
+// TemplatesConfig is a configuration of the templates used to generate new sources.
+// Templates that aren't set use the default ones.
+type TemplatesConfig struct {
+	// QueryExtended is the path of the template of sources extending a root type, eg. extend type Query.
+	QueryExtended string `yaml:"query_extended" desc:"Path of the Go template of sources extending a root type, executed with QueryViewData."`
+	// Query is the path of the template of the source defining a root type, eg. type Query.
+	Query string `yaml:"query" desc:"Path of the Go template of the source defining a root type, executed with QueryViewData."`
+	// Object is the path of the template of sources of object types.
+	Object string `yaml:"object" desc:"Path of the Go template of sources of object types, executed with TypeViewData."`
+}
+
 func loadConfig(cfgFilePath string) (*SplitterConfig, error) {
 	cfgFilePath, err := findCfg(cfgFilePath)
 	if err != nil {
@@ -56,16 +174,67 @@ func loadConfig(cfgFilePath string) (*SplitterConfig, error) {
 		return nil, fmt.Errorf("unable to read config: %w", err)
 	}
 
-	return readConfig(bytes.NewReader(b))
+	cfg, err := readConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.dir = filepath.Dir(cfgFilePath)
+
+	return cfg, nil
+}
+
+// loadTemplates parses the custom templates of the config, using the default ones for those that aren't set.
+func (c *SplitterConfig) loadTemplates() (*Templates, error) {
+	templates := *defaultTemplates
+
+	for _, tpl := range []struct {
+		path string
+		tmpl **template.Template
+	}{
+		{c.Templates.QueryExtended, &templates.QueryExtended},
+		{c.Templates.Query, &templates.Query},
+		{c.Templates.Object, &templates.Object},
+	} {
+		if tpl.path == "" {
+			continue
+		}
+
+		b, err := os.ReadFile(c.path(tpl.path))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read template: %w", err)
+		}
+
+		if *tpl.tmpl, err = template.New(filepath.Base(tpl.path)).Parse(string(b)); err != nil {
+			return nil, fmt.Errorf("unable to parse template %s: %w", tpl.path, err)
+		}
+	}
+
+	return &templates, nil
+}
+
+// path returns the given path relative to the config file directory, unless it's absolute.
+func (c *SplitterConfig) path(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(c.dir, p)
 }
 
 func readConfig(cfgFile io.Reader) (*SplitterConfig, error) {
 	cfg := &PluginsCfg{}
 
-	dec := yaml.NewDecoder(cfgFile)
-	dec.KnownFields(false)
+	var doc yaml.Node
+	if err := yaml.NewDecoder(cfgFile).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unable to parse config: %w", err)
+	}
 
-	if err := dec.Decode(cfg); err != nil {
+	// older config formats are migrated in memory before being decoded
+	if _, err := migrateConfigNode(doc.Content[0]); err != nil {
+		return nil, err
+	}
+
+	if err := doc.Decode(cfg); err != nil {
 		return nil, fmt.Errorf("unable to parse config: %w", err)
 	}
 
@@ -77,6 +246,26 @@ func readConfig(cfgFile io.Reader) (*SplitterConfig, error) {
 		return nil, fmt.Errorf("no type or query configs defined")
 	}
 
+	if err := checkEnums(cfg.Splitter); err != nil {
+		return nil, err
+	}
+
+	if cfg.Splitter.Mode == ModeResolvers && cfg.Splitter.WriteSources {
+		return nil, fmt.Errorf("write_sources can't be used with mode %s, which leaves the sources unchanged", ModeResolvers)
+	}
+
+	if cfg.Splitter.SplitModels && cfg.Splitter.ModelPackages {
+		return nil, fmt.Errorf("split_models can't be used with model_packages, which moves the models to packages instead of files")
+	}
+
+	if err := cfg.Splitter.checkFilenameTemplates(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Splitter.checkKeepResolvers(); err != nil {
+		return nil, err
+	}
+
 	if err := cfg.Splitter.compileMatches(); err != nil {
 		return nil, err
 	}
@@ -84,6 +273,42 @@ func readConfig(cfgFile io.Reader) (*SplitterConfig, error) {
 	return cfg.Splitter, nil
 }
 
+// checkEnums checks the string fields of the config that have an enum tag.
+func checkEnums(cfg *SplitterConfig) error {
+	v := reflect.ValueOf(cfg).Elem()
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		if err := checkEnum(field, v.Field(i).String()); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkEnum checks that the value of a field with an enum tag is one of the allowed values,
+// an empty value meaning the default one.
+func checkEnum(field reflect.StructField, value string) error {
+	enum := field.Tag.Get("enum")
+	if enum == "" || value == "" {
+		return nil
+	}
+
+	values := strings.Split(enum, ",")
+	for _, allowed := range values {
+		if value == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%q must be one of %s, got %q", yamlName(field), strings.Join(values, ", "), value)
+}
+
 // findCfg searches for the config file in this directory and all parents up the tree
 // looking for the closest match.
 // Copied from 99designs/gqlgen.
@@ -137,24 +362,82 @@ func (c *SplitterConfig) compileMatches() error {
 	return nil
 }
 
+// matchString returns whether any of the compiled regexes matches the given query name.
+func (q *QuerySplitConfig) matchString(queryName string) bool {
+	for _, m := range q.matches {
+		if m.MatchString(queryName) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingRegex returns the first of the Matches regexes that matches the given query name.
+func (q *QuerySplitConfig) matchingRegex(queryName string) string {
+	if mi := q.matchingIndex(queryName); mi >= 0 {
+		return q.Matches[mi]
+	}
+	return ""
+}
+
+// matchingIndex returns the index of the first of the Matches regexes that matches the given query name, or -1.
+func (q *QuerySplitConfig) matchingIndex(queryName string) int {
+	for mi, m := range q.matches {
+		if m.MatchString(queryName) {
+			return mi
+		}
+	}
+	return -1
+}
+
+// FindRule returns the first query config matching the given query name.
+func (qs QuerySplitConfigs) FindRule(queryName string) (*QuerySplitConfig, bool) {
+	if qi := qs.findRuleIndex(queryName); qi >= 0 {
+		return &qs[qi], true
+	}
+	return nil, false
+}
+
+// findRuleIndex returns the index of the first query config matching the given query name, or -1.
+func (qs QuerySplitConfigs) findRuleIndex(queryName string) int {
+	for qi := range qs {
+		if qs[qi].matchString(queryName) {
+			return qi
+		}
+	}
+	return -1
+}
+
 // FindResolverPrefix returns the resolver prefix for the given query name.
 func (qs QuerySplitConfigs) FindResolverPrefix(queryName string) (string, bool) {
-	for _, q := range qs {
-		for _, m := range q.matches {
-			if m.MatchString(queryName) {
-				return q.ResolverPrefix, true
-			}
-		}
+	if q, ok := qs.FindRule(queryName); ok {
+		return q.ResolverPrefix, true
 	}
 	return "", false
 }
 
+// FindRule returns the first type config matching the given type name.
+func (ts TypeSplitConfigs) FindRule(typeName string) (*TypeSplitConfig, bool) {
+	if ti := ts.findRuleIndex(typeName); ti >= 0 {
+		return &ts[ti], true
+	}
+	return nil, false
+}
+
+// findRuleIndex returns the index of the first type config matching the given type name, or -1.
+func (ts TypeSplitConfigs) findRuleIndex(typeName string) int {
+	for ti := range ts {
+		if ts[ti].Name == typeName {
+			return ti
+		}
+	}
+	return -1
+}
+
 // FindResolverPrefix returns the resolver prefix for the given type name.
 func (ts TypeSplitConfigs) FindResolverPrefix(typeName string) (string, bool) {
-	for _, t := range ts {
-		if t.Name == typeName {
-			return t.ResolverPrefix, true
-		}
+	if t, ok := ts.FindRule(typeName); ok {
+		return t.ResolverPrefix, true
 	}
 	return "", false
 }
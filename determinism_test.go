@@ -0,0 +1,106 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// commentsSource adds inputs, a scalar and a type to the test sources, next to an extension of Query,
+// so that several sources feed the same new sources.
+const commentsSource = `scalar Time
+
+input CommentFilter {
+    postId: ID
+    since: Time
+}
+
+type Comment {
+    """The ID of the comment"""
+    id: ID!
+
+    """The post of the comment"""
+    post: Post!
+
+    """The date of the comment"""
+    createdAt: Time!
+}
+
+extend type Query {
+    """Get a comment by ID"""
+    getComment(id: ID!): Comment
+
+    """Get the comments of posts"""
+    getPostComments(filter: CommentFilter): [Comment!]!
+}
+`
+
+func Test_MutateConfig_Deterministic(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, src := range getTestSources(t, false) {
+		if err := os.WriteFile(filepath.Join(dir, src.Name), []byte(src.Input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "comments.graphql"), []byte(commentsSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := getTestConfig(t, `
+types_splitter:
+  header: true
+  types:
+    - name: Comment
+      prefix: blog
+    - name: Post
+      prefix: blog
+    - name: Editor
+      prefix: blog
+  queries:
+    - prefix: blog
+      matches:
+        - post|comment
+        - editor
+`)
+
+	run := func(t *testing.T) string {
+		t.Helper()
+
+		splitter := &TypesSplitterPlugin{cfg: cfg}
+		genCfg := loadGenConfigFromDir(t, dir)
+		if err := splitter.MutateConfig(genCfg); err != nil {
+			t.Fatal(err)
+		}
+
+		manifest, err := yaml.Marshal(splitter.Manifest())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sb := &strings.Builder{}
+		for _, src := range genCfg.Sources {
+			fmt.Fprintf(sb, "=== %s\n%s\n", filepath.Base(src.Name), src.Input)
+		}
+		fmt.Fprintf(sb, "=== manifest\n%s\n=== warnings\n%s\n", manifest, strings.Join(splitter.Warnings(), "\n"))
+
+		return sb.String()
+	}
+
+	expected := run(t)
+
+	// types defined after inputs and scalars in the same source are split too
+	if !strings.Contains(expected, "=== blog.graphql\n") || !strings.Contains(expected, "type Comment {") {
+		t.Fatalf("expected Comment to be split into blog.graphql:\n%s", expected)
+	}
+
+	for i := 0; i < 50; i++ {
+		if actual := run(t); actual != expected {
+			t.Fatalf("run %d differs from the first one:\n%s", i+2, unifiedDiff("first", "actual", expected, actual))
+		}
+	}
+}
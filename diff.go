@@ -0,0 +1,224 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes in unified diffs.
+const diffContext = 3
+
+// devNull is the name used in unified diffs for created and deleted sources.
+const devNull = "/dev/null"
+
+type diffOpKind int
+
+const (
+	diffEqual diffOpKind = iota
+	diffDelete
+	diffInsert
+)
+
+// diffOp is a line of an edit script, with its index in both inputs.
+type diffOp struct {
+	kind diffOpKind
+	a, b int
+}
+
+// unifiedDiff returns the unified diff between two inputs, or an empty string if they are equal.
+func unifiedDiff(aName, bName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	aLines, bLines := splitLines(a), splitLines(b)
+	ops := diffLines(aLines, bLines)
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", aName, bName)
+
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].kind == diffEqual {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// extend the hunk until there are more than 2*diffContext equal lines in a row
+		end := start
+		for i, equals := start, 0; i < len(ops); i++ {
+			if ops[i].kind != diffEqual {
+				equals = 0
+				end = i + 1
+				continue
+			}
+			if equals++; equals > 2*diffContext {
+				break
+			}
+		}
+
+		hunkStart := start - diffContext
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		hunkEnd := end + diffContext
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+		writeHunk(sb, ops[hunkStart:hunkEnd], aLines, bLines)
+
+		start = hunkEnd
+	}
+
+	return sb.String()
+}
+
+// writeHunk writes a hunk of the unified diff.
+func writeHunk(sb *strings.Builder, ops []diffOp, aLines, bLines []string) {
+	aStart, bStart := -1, -1
+	aCount, bCount := 0, 0
+
+	for _, op := range ops {
+		if op.kind != diffInsert {
+			if aStart < 0 {
+				aStart = op.a
+			}
+			aCount++
+		}
+		if op.kind != diffDelete {
+			if bStart < 0 {
+				bStart = op.b
+			}
+			bCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount, ops[0].a), hunkRange(bStart, bCount, ops[0].b))
+
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			writeDiffLine(sb, ' ', aLines[op.a])
+		case diffDelete:
+			writeDiffLine(sb, '-', aLines[op.a])
+		case diffInsert:
+			writeDiffLine(sb, '+', bLines[op.b])
+		}
+	}
+}
+
+// hunkRange returns the range of a hunk, where empty ranges start at the line before the hunk.
+func hunkRange(start, count, fallback int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", fallback)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeDiffLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	if strings.HasSuffix(line, "\n") {
+		sb.WriteString(line)
+		return
+	}
+	sb.WriteString(line)
+	sb.WriteString("\n\\ No newline at end of file\n")
+}
+
+// splitLines splits the input in lines, keeping the line endings.
+func splitLines(input string) []string {
+	lines := strings.SplitAfter(input, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script between two lists of lines, using Myers' algorithm.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, d, offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrack builds the edit script from the trace of Myers' algorithm.
+func backtrack(trace [][]int, a, b []string, d, offset int) []diffOp {
+	x, y := len(a), len(b)
+	var ops []diffOp
+
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: diffEqual, a: x, b: y})
+		}
+
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{kind: diffInsert, a: x, b: y})
+		} else {
+			x--
+			ops = append(ops, diffOp{kind: diffDelete, a: x, b: y})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, diffOp{kind: diffEqual, a: x, b: y})
+	}
+
+	// reverse the ops
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
@@ -0,0 +1,59 @@
+package types_splitter_plugin
+
+import "testing"
+
+func Test_unifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{
+			name: "equal inputs",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "distant changes are split in hunks",
+			a:    "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n",
+			b:    "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn",
+			want: `--- a
++++ b
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+\ No newline at end of file
+`,
+		},
+		{
+			name: "deleted input",
+			a:    "a\nb\n",
+			b:    "",
+			want: `--- a
++++ b
+@@ -1,2 +0,0 @@
+-a
+-b
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a", "b", tt.a, tt.b); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
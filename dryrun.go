@@ -0,0 +1,105 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// WithDryRun computes the split without changing the gqlgen config, the lock file or the sources on disk.
+// The unified diff of the schema changes is written to w, if not nil, and returned by Diff.
+func WithDryRun(w io.Writer) Option {
+	return func(s *TypesSplitterPlugin) {
+		s.dryRun = true
+		s.dryRunOut = w
+	}
+}
+
+// Diff returns the unified diff of the schema changes computed by the last dry-run.
+func (s *TypesSplitterPlugin) Diff() string {
+	return s.diff
+}
+
+// dryRunSplit splits a copy of the given config, and computes the diff of the schema changes.
+func (s *TypesSplitterPlugin) dryRunSplit(genCfg *config.Config) error {
+	cfgCopy, err := copyConfig(genCfg)
+	if err != nil {
+		return err
+	}
+
+	if err = s.split(cfgCopy); err != nil {
+		return err
+	}
+
+	s.diff = sourcesDiff(genCfg.Sources, cfgCopy.Sources)
+
+	if s.dryRunOut != nil {
+		if _, err = io.WriteString(s.dryRunOut, s.diff); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// copyConfig returns a copy of the given config, with copies of its sources and a schema loaded from them,
+// so that splitting the copy doesn't change the original config.
+func copyConfig(genCfg *config.Config) (*config.Config, error) {
+	cfgCopy := *genCfg
+	cfgCopy.Sources = make([]*ast.Source, 0, len(genCfg.Sources))
+
+	for _, src := range genCfg.Sources {
+		srcCopy := *src
+		cfgCopy.Sources = append(cfgCopy.Sources, &srcCopy)
+	}
+
+	schema, err := gqlparser.LoadSchema(cfgCopy.Sources...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema copy: %w", err)
+	}
+	cfgCopy.Schema = schema
+
+	return &cfgCopy, nil
+}
+
+// sourcesDiff returns the unified diff between two lists of sources, sorted by source name.
+// Sources only found in the first list are deleted, and sources only found in the second list are created.
+func sourcesDiff(before, after []*ast.Source) string {
+	beforeInputs := map[string]string{}
+	afterInputs := map[string]string{}
+	var names []string
+
+	for _, src := range before {
+		beforeInputs[src.Name] = src.Input
+		names = append(names, src.Name)
+	}
+	for _, src := range after {
+		if _, ok := beforeInputs[src.Name]; !ok {
+			names = append(names, src.Name)
+		}
+		afterInputs[src.Name] = src.Input
+	}
+	sort.Strings(names)
+
+	sb := &strings.Builder{}
+	for _, name := range names {
+		beforeInput, inBefore := beforeInputs[name]
+		afterInput, inAfter := afterInputs[name]
+
+		switch {
+		case !inBefore:
+			sb.WriteString(unifiedDiff(devNull, name, "", afterInput))
+		case !inAfter:
+			sb.WriteString(unifiedDiff(name, devNull, beforeInput, ""))
+		default:
+			sb.WriteString(unifiedDiff(name, name, beforeInput, afterInput))
+		}
+	}
+
+	return sb.String()
+}
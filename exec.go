@@ -0,0 +1,172 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	goast "go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+)
+
+// execFilenameTemplate is the filename template of the exec files gqlgen uses with the follow-schema layout by default.
+const execFilenameTemplate = "{name}.generated.go"
+
+// execNotice is the notice of the exec files split by SplitExec.
+var execNotice = fmt.Sprintf(splitNotice, "the exec files")
+
+// configureExec checks that the exec config of gqlgen has the follow-schema layout, with which the exec code of the
+// types of each source is generated in its own file, eg. users.generated.go for users.graphql. The layout is never
+// changed behind the back of the user, only the filename template is defaulted.
+func (s *TypesSplitterPlugin) configureExec() error {
+	exec := &s.genCfg.Exec
+	if !s.cfg.SplitExec || !exec.IsDefined() {
+		return nil
+	}
+
+	if exec.Layout != config.ExecLayoutFollowSchema {
+		return fmt.Errorf("split_exec requires the exec layout follow-schema with a dir, got %s", exec.Layout)
+	}
+
+	if exec.FilenameTemplate == "" {
+		exec.FilenameTemplate = execFilenameTemplate
+	} else if !strings.Contains(exec.FilenameTemplate, "{name}") {
+		return fmt.Errorf("exec filename_template %q must contain {name}, or the exec code of every source is generated in the same file", exec.FilenameTemplate)
+	}
+
+	return nil
+}
+
+// removeSplitExec deletes the exec files split by the last call to SplitExec. gqlgen generates the exec code of the
+// root fields in the files of the root types again, so they would be declared twice.
+func (s *TypesSplitterPlugin) removeSplitExec(data *codegen.Data) error {
+	exec := data.Config.Exec
+	if !s.cfg.SplitExec || s.dryRun || exec.Layout != config.ExecLayoutFollowSchema {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(exec.Dir(), "*.go"))
+	if err != nil {
+		return fmt.Errorf("failed to list exec files: %w", err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read exec file: %w", err)
+		}
+		if bytes.Contains(content, []byte(execNotice)) {
+			if err = os.Remove(file); err != nil {
+				return fmt.Errorf("failed to delete exec file: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// SplitExec moves the exec code of the split root fields, eg. Query.getUser, from the exec file of their root type
+// to the exec file of their source, eg. users.queries.generated.go. gqlgen generates the exec code of all the fields
+// of a type in the same file, after the plugins too, so it must be called once api.Generate returned.
+func (s *TypesSplitterPlugin) SplitExec() error {
+	if !s.cfg.SplitExec || s.dryRun {
+		return nil
+	}
+	if s.data == nil {
+		return errors.New("SplitExec must be called after api.Generate")
+	}
+
+	exec := s.data.Config.Exec
+	if exec.Layout != config.ExecLayoutFollowSchema {
+		return nil
+	}
+
+	// the exec file of each function of the root fields, named after the source of the field
+	targets := make(map[string]string)
+	for _, obj := range []*codegen.Object{s.data.QueryRoot, s.data.MutationRoot, s.data.SubscriptionRoot} {
+		if obj == nil {
+			continue
+		}
+
+		for _, field := range obj.Fields {
+			// introspection fields have no source of the schema
+			if strings.HasPrefix(field.Name, "__") || field.Position == nil || field.Position.Src == nil {
+				continue
+			}
+
+			target := resolverFileName(exec.Dir(), field.Position.Src.Name, exec.FilenameTemplate)
+			for _, name := range []string{
+				"_" + obj.Name + "_" + field.Name,
+				"fieldContext_" + obj.Name + "_" + field.Name,
+				"field_" + obj.Name + "_" + field.Name + "_args",
+			} {
+				targets[name] = target
+			}
+		}
+	}
+
+	files, err := loadGoFiles(exec.Dir())
+	if err != nil {
+		return err
+	}
+
+	edits := make(map[string]*fileEdits)
+	editsOf := func(p string) *fileEdits {
+		if edits[p] == nil {
+			edits[p] = &fileEdits{replaces: make(map[int]replace)}
+			if f := files[p]; f != nil {
+				edits[p].removedImports = f.file.Imports
+			}
+		}
+		return edits[p]
+	}
+
+	// the header of the new files is the one of the first file functions are moved from
+	headers := make(map[string]string)
+	for _, p := range sortedKeys(files) {
+		f := files[p]
+		for _, decl := range f.file.Decls {
+			fn, ok := decl.(*goast.FuncDecl)
+			if !ok || fn.Recv == nil {
+				continue
+			}
+
+			target := targets[fn.Name.Name]
+			if target == "" || target == p {
+				continue
+			}
+
+			start, end := f.span(fn)
+			editsOf(p).replaces[start] = replace{end: end}
+
+			targetEdits := editsOf(target)
+			targetEdits.appends = append(targetEdits.appends, f.source(fn))
+			targetEdits.imports = append(targetEdits.imports, f.file.Imports...)
+			targetEdits.removedImports = append(targetEdits.removedImports, f.file.Imports...)
+			if _, ok := headers[target]; !ok {
+				headers[target] = splitHeader(f, execNotice, f.file.Name.Name)
+			}
+		}
+	}
+
+	for _, p := range sortedKeys(edits) {
+		e := edits[p]
+		if f := files[p]; f != nil {
+			if err = e.apply(f); err != nil {
+				return err
+			}
+			continue
+		}
+
+		src := headers[p] + "\n" + strings.Join(e.appends, "\n\n") + "\n"
+		if err = writeGoFile(p, []byte(src), e.imports, e.removedImports); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package types_splitter_plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func Test_configureExec(t *testing.T) {
+	tests := []struct {
+		name     string
+		exec     config.ExecConfig
+		expected config.ExecConfig
+		err      string
+	}{
+		{
+			name: "single file",
+			exec: config.ExecConfig{Layout: config.ExecLayoutSingleFile, Filename: "/app/graph/generated.go"},
+			err:  "split_exec requires the exec layout follow-schema with a dir, got single-file",
+		},
+		{
+			name:     "default filename template",
+			exec:     config.ExecConfig{Layout: config.ExecLayoutFollowSchema, DirName: "/app/graph"},
+			expected: config.ExecConfig{Layout: config.ExecLayoutFollowSchema, DirName: "/app/graph", FilenameTemplate: "{name}.generated.go"},
+		},
+		{
+			name:     "follow schema",
+			exec:     config.ExecConfig{Layout: config.ExecLayoutFollowSchema, DirName: "/app/graph", FilenameTemplate: "{name}.exec.go"},
+			expected: config.ExecConfig{Layout: config.ExecLayoutFollowSchema, DirName: "/app/graph", FilenameTemplate: "{name}.exec.go"},
+		},
+		{
+			name: "filename template without name",
+			exec: config.ExecConfig{Layout: config.ExecLayoutFollowSchema, DirName: "/app/graph", FilenameTemplate: "exec.go"},
+			err:  `exec filename_template "exec.go" must contain {name}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splitter := &TypesSplitterPlugin{
+				cfg:    &SplitterConfig{SplitExec: true},
+				genCfg: &config.Config{Exec: tt.exec},
+			}
+
+			err := splitter.configureExec()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if splitter.genCfg.Exec != tt.expected {
+				t.Errorf("expected exec config %+v, got %+v", tt.expected, splitter.genCfg.Exec)
+			}
+		})
+	}
+}
+
+func Test_SplitExec(t *testing.T) {
+	dir := t.TempDir()
+	graphDir := filepath.Join(dir, "graph")
+
+	// the exec package as generated by gqlgen with the follow-schema layout, before SplitExec runs
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.20\n",
+		"graph/root_.generated.go": `// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+type executionContext struct{}
+`,
+		"graph/queries.generated.go": `// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+func (ec *executionContext) _Query(ctx context.Context) string {
+	return ec._Query_getUser(ctx) + ec._Query_node(ctx) + ec._Query___type(ctx)
+}
+
+func (ec *executionContext) field_Query_getUser_args(id int) string {
+	return fmt.Sprint(id)
+}
+
+// _Query_getUser resolves Query.getUser
+func (ec *executionContext) _Query_getUser(ctx context.Context) string {
+	return strconv.Itoa(len(ec.fieldContext_Query_getUser(ctx))) + ec.field_Query_getUser_args(1)
+}
+
+func (ec *executionContext) fieldContext_Query_getUser(ctx context.Context) string {
+	return "getUser"
+}
+
+func (ec *executionContext) _Query_node(ctx context.Context) string {
+	return "node"
+}
+
+func (ec *executionContext) _Query___type(ctx context.Context) string {
+	return "__type"
+}
+`,
+	}
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// split by a previous run
+	stale := filepath.Join(graphDir, "editors.mutations.generated.go")
+	if err := os.WriteFile(stale, []byte("package graph\n\n"+execNotice+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := &ast.Source{Name: "graph/queries.graphql"}
+	users := &ast.Source{Name: "graph/users.queries.graphql"}
+	data := &codegen.Data{
+		Config: &config.Config{
+			Exec: config.ExecConfig{Layout: config.ExecLayoutFollowSchema, DirName: graphDir, FilenameTemplate: "{name}.generated.go"},
+		},
+		QueryRoot: &codegen.Object{
+			Definition: &ast.Definition{Name: "Query", Position: &ast.Position{Src: queries}},
+			Fields: []*codegen.Field{
+				{FieldDefinition: &ast.FieldDefinition{Name: "getUser", Position: &ast.Position{Src: users}}},
+				{FieldDefinition: &ast.FieldDefinition{Name: "node", Position: &ast.Position{Src: queries}}},
+				{FieldDefinition: &ast.FieldDefinition{Name: "__type"}},
+			},
+		},
+	}
+
+	splitter := &TypesSplitterPlugin{cfg: &SplitterConfig{SplitExec: true}}
+	if err := splitter.SplitExec(); err == nil {
+		t.Error("expected an error before api.Generate")
+	}
+
+	if err := splitter.GenerateCode(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected the exec file split by the previous run to be deleted, got %v", err)
+	}
+
+	if err := splitter.SplitExec(); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(graphDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	split := read("users.queries.generated.go")
+	for _, expected := range []string{
+		"// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.\n" + execNotice + "\n\npackage graph\n",
+		"\t\"fmt\"\n\t\"strconv\"\n",
+		"// _Query_getUser resolves Query.getUser\nfunc (ec *executionContext) _Query_getUser(",
+		"func (ec *executionContext) fieldContext_Query_getUser(",
+		"func (ec *executionContext) field_Query_getUser_args(",
+	} {
+		if !strings.Contains(split, expected) {
+			t.Errorf("expected users.queries.generated.go to contain %q, got:\n%s", expected, split)
+		}
+	}
+
+	root := read("queries.generated.go")
+	if strings.Contains(root, "func (ec *executionContext) _Query_getUser(") || strings.Contains(root, "strconv") {
+		t.Errorf("expected the exec code of getUser to be moved, got:\n%s", root)
+	}
+	for _, expected := range []string{"func (ec *executionContext) _Query(", "_Query_node(", "_Query___type("} {
+		if !strings.Contains(root, expected) {
+			t.Errorf("expected queries.generated.go to contain %q, got:\n%s", expected, root)
+		}
+	}
+
+	if _, err := exec.LookPath("go"); err == nil {
+		cmd := exec.Command("go", "build", "./...")
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("expected the exec package to build, got %v:\n%s", err, out)
+		}
+	}
+}
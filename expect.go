@@ -0,0 +1,78 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// fieldPlacementKey returns the key of a root field in placements, eg. Query.getUser.
+func fieldPlacementKey(rootDef *ast.Definition, field *FieldDefinition) string {
+	return rootDef.Name + "." + field.Name
+}
+
+// findPlacement returns the prefix a root field or type was split into.
+// Root fields can be referenced by their name only (eg. getUser) as long as it isn't ambiguous.
+func (s *TypesSplitterPlugin) findPlacement(name string) (string, error) {
+	if prefix, ok := s.placements[name]; ok {
+		return prefix, nil
+	}
+
+	var keys []string
+	for key := range s.placements {
+		if _, fieldName, ok := strings.Cut(key, "."); ok && fieldName == name {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	switch len(keys) {
+	case 0:
+		return "", fmt.Errorf("no root field or object type named %s", name)
+	case 1:
+		return s.placements[keys[0]], nil
+	default:
+		return "", fmt.Errorf("%s is ambiguous, use one of %s", name, strings.Join(keys, ", "))
+	}
+}
+
+// checkExpectations checks that every field and type of the Expect config ended up in its expected prefix,
+// and returns an error with the diff of all the differences otherwise.
+func (s *TypesSplitterPlugin) checkExpectations() error {
+	names := make([]string, 0, len(s.cfg.Expect))
+	for name := range s.cfg.Expect {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var diff []string
+	for _, name := range names {
+		expected := s.cfg.Expect[name]
+
+		actual, err := s.findPlacement(name)
+		if err != nil {
+			diff = append(diff, fmt.Sprintf("- %s: %s", name, quotePrefix(expected)), fmt.Sprintf("+ %s: (%s)", name, err))
+			continue
+		}
+
+		if actual != expected {
+			diff = append(diff, fmt.Sprintf("- %s: %s", name, quotePrefix(expected)), fmt.Sprintf("+ %s: %s", name, quotePrefix(actual)))
+		}
+	}
+
+	if len(diff) > 0 {
+		return fmt.Errorf("placements don't match the expectations:\n--- expected\n+++ actual\n%s", strings.Join(diff, "\n"))
+	}
+
+	return nil
+}
+
+// quotePrefix quotes empty prefixes so they are visible in messages.
+func quotePrefix(prefix string) string {
+	if prefix == "" {
+		return `""`
+	}
+	return prefix
+}
@@ -0,0 +1,115 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const (
+	// FormatTemplate prints new sources with their template.
+	FormatTemplate = "template"
+	// FormatCanonical prints new sources with the gqlparser formatter.
+	FormatCanonical = "canonical"
+)
+
+// formatSource prints the input of a new source with the gqlparser formatter. Sources with comments are
+// left as generated by their template, as the parser drops comments.
+func (s *TypesSplitterPlugin) formatSource(src *Source) error {
+	if hasComments(src.Input) {
+		s.warnf("%s has comments that the formatter would drop, it isn't formatted", src.Name)
+		return nil
+	}
+
+	doc, err := parser.ParseSchema(&ast.Source{Name: src.Name, Input: src.Input})
+	if err != nil {
+		return fmt.Errorf("unable to format %s: %w", src.Name, err)
+	}
+
+	buf := &bytes.Buffer{}
+	formatter.NewFormatter(buf).FormatSchemaDocument(doc)
+
+	src.Input = buf.String()
+	src.formatted = true
+
+	return nil
+}
+
+// relocateFormatted points the fields and types of a formatted source to their position in the given
+// source, where its input was added. Their content was reprinted, so they are found by name in the
+// parsed source rather than by content.
+func relocateFormatted(src *ast.Source, newSrc *Source) error {
+	doc, err := parser.ParseSchema(src)
+	if err != nil {
+		return fmt.Errorf("unable to parse formatted source %s: %w", src.Name, err)
+	}
+
+	positions := make(map[string]*ast.Position)
+	for _, defs := range []ast.DefinitionList{doc.Definitions, doc.Extensions} {
+		for _, def := range defs {
+			positions[def.Name] = def.Position
+			for _, field := range def.Fields {
+				positions[def.Name+"."+field.Name] = field.Position
+			}
+		}
+	}
+
+	for _, field := range newSrc.Fields {
+		moveTo(field, positions[newSrc.typeName()+"."+field.Name])
+	}
+
+	for _, def := range newSrc.Types {
+		moveTo(def, positions[def.Name])
+		for _, field := range def.Fields {
+			moveTo(field, positions[def.Name+"."+field.Name])
+		}
+	}
+
+	return nil
+}
+
+// moveTo sets the position and the actual position of the Positioner to the given position.
+func moveTo(p Positioner, pos *ast.Position) {
+	if pos == nil {
+		return
+	}
+
+	for _, dst := range []*ast.Position{p.Pos(), p.ActualPos()} {
+		dst.Src = pos.Src
+		dst.Line = pos.Line
+		dst.Column = pos.Column
+		dst.Start = pos.Start
+		dst.End = pos.End
+	}
+}
+
+// hasComments returns whether the input has comments, outside of strings and descriptions.
+func hasComments(input string) bool {
+	for i := 0; i < len(input); i++ {
+		switch {
+		case input[i] == '#':
+			return true
+		case len(input) >= i+3 && input[i:i+3] == `"""`:
+			// block strings end with the next unescaped """
+			i += 3
+			for i < len(input) && !(len(input) >= i+3 && input[i:i+3] == `"""` && input[i-1] != '\\') {
+				i++
+			}
+			i += 2
+		case input[i] == '"':
+			// strings end with the next unescaped " on the same line
+			i++
+			for i < len(input) && input[i] != '"' && input[i] != '\n' {
+				if input[i] == '\\' {
+					i++
+				}
+				i++
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+package types_splitter_plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func Test_hasComments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"type Post {\n  id: ID!\n}\n", false},
+		{"type Post {\n  id: ID! # the id\n}\n", true},
+		{"# posts\ntype Post {\n  id: ID!\n}\n", true},
+		{"type Post {\n  \"\"\"The #1 post\"\"\"\n  id: ID!\n}\n", false},
+		{"type Post {\n  \"\"\"\n  An escaped \\\"\"\" and a #hashtag\n  \"\"\"\n  id: ID!\n}\n", false},
+		{"type Post {\n  \"The \\\"#1\\\" post\"\n  id: ID!\n}\n", false},
+		{"type Post {\n  id: ID! @tag(name: \"#posts\") # the id\n}\n", true},
+	}
+
+	for _, tt := range tests {
+		if actual := hasComments(tt.input); actual != tt.expected {
+			t.Errorf("hasComments(%q) = %v, expected %v", tt.input, actual, tt.expected)
+		}
+	}
+}
+
+func Test_MutateConfig_Format(t *testing.T) {
+	cfg := getTestConfig(t, `
+types_splitter:
+  format: canonical
+  types:
+    - name: Editor
+      prefix: editors
+  queries:
+    - prefix: posts
+      matches:
+        - post
+`)
+
+	splitter := &TypesSplitterPlugin{cfg: cfg}
+	genCfg := getTestGenConfig(t)
+	if err := splitter.MutateConfig(genCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	sources := make(map[string]*ast.Source)
+	for _, src := range genCfg.Sources {
+		sources[src.Name] = src
+	}
+
+	expected := `extend type Query {
+	"""Get a post by ID"""
+	getPost(id: ID!): Post @auth
+	"""Get posts written by an editor, with optional pagination parameters"""
+	getPostsByEditor(editorId: ID!, first: Int, after: String, last: Int, before: String): PostConnection! @auth @cacheControl(maxAge: 10, scope: PRIVATE)
+}
+`
+	if actual := sources["posts.queries.graphql"].Input; actual != expected {
+		t.Errorf("unexpected posts.queries.graphql:\n%s", actual)
+	}
+
+	// the positions of the moved fields match the formatted sources
+	for _, move := range splitter.Manifest().Moves {
+		doc, err := parser.ParseSchema(sources[move.Source])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		typeName, fieldName, _ := strings.Cut(move.Name, ".")
+		for _, def := range append(doc.Definitions, doc.Extensions...) {
+			if def.Name != typeName {
+				continue
+			}
+			if field := def.Fields.ForName(fieldName); field != nil && field.Position.Line != move.Line {
+				t.Errorf("expected %s at line %d, got %d", move.Name, field.Position.Line, move.Line)
+			}
+		}
+	}
+
+	// comments would be lost, so the editors source isn't formatted
+	if !strings.Contains(sources["editors.graphql"].Input, "email: String! # This is a comment") {
+		t.Errorf("unexpected editors.graphql:\n%s", sources["editors.graphql"].Input)
+	}
+	if warnings := strings.Join(splitter.Warnings(), "\n"); !strings.Contains(warnings, "editors.graphql has comments that the formatter would drop") {
+		t.Errorf("unexpected warnings:\n%s", warnings)
+	}
+}
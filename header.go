@@ -0,0 +1,114 @@
+package types_splitter_plugin
+
+import (
+	"sort"
+	"strings"
+)
+
+// headerMarker is the first line of the header of new sources, used to recognise the sources
+// produced by a previous split.
+const headerMarker = "# Code generated by " + PluginName + ", edit the original sources or the split rules instead."
+
+// sourceHeader is the provenance of a source produced by the split, as written in its header.
+type sourceHeader struct {
+	Prefix    string
+	Rules     []string
+	Originals []string
+}
+
+// String returns the header comment, followed by an empty line.
+func (h *sourceHeader) String() string {
+	sb := &strings.Builder{}
+	sb.WriteString(headerMarker + "\n")
+	sb.WriteString("# prefix: " + h.Prefix + "\n")
+	sb.WriteString("# rules: " + strings.Join(h.Rules, ", ") + "\n")
+	sb.WriteString("# originals: " + strings.Join(h.Originals, ", ") + "\n\n")
+
+	return sb.String()
+}
+
+// cutHeader returns the header of an input produced by the split and the input without it,
+// or nil and the unchanged input if it doesn't start with a header.
+func cutHeader(input string) (*sourceHeader, string) {
+	rest, ok := strings.CutPrefix(input, headerMarker+"\n")
+	if !ok {
+		return nil, input
+	}
+
+	header := &sourceHeader{}
+	for strings.HasPrefix(rest, "#") {
+		line, next, _ := strings.Cut(rest, "\n")
+		rest = next
+
+		key, value, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":")
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "prefix":
+			header.Prefix = value
+		case "rules":
+			header.Rules = splitHeaderList(value)
+		case "originals":
+			header.Originals = splitHeaderList(value)
+		}
+	}
+
+	return header, strings.TrimLeft(rest, "\n")
+}
+
+// splitHeaderList splits a comma separated list of the header.
+func splitHeaderList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// header returns the header of a new source, merged with the header of the source it replaces if any,
+// or an empty string if headers are disabled.
+func (s *TypesSplitterPlugin) header(src *Source, prev *sourceHeader) string {
+	if !s.cfg.Header {
+		return ""
+	}
+
+	header := &sourceHeader{Prefix: src.Prefix}
+	rules, originals := src.provenance()
+	if prev != nil {
+		rules = append(prev.Rules, rules...)
+		originals = append(prev.Originals, originals...)
+	}
+
+	// sources produced by a previous split are traced back to their own originals
+	var resolved []string
+	for _, original := range originals {
+		if origHeader := s.sourceHeaders[original]; origHeader != nil {
+			resolved = append(resolved, origHeader.Originals...)
+			continue
+		}
+		resolved = append(resolved, original)
+	}
+
+	header.Rules = uniqueStrings(rules)
+	header.Originals = uniqueStrings(resolved)
+	sort.Strings(header.Originals)
+
+	return header.String()
+}
+
+// uniqueStrings returns the list without duplicates, keeping the first occurrences.
+func uniqueStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	unique := make([]string, 0, len(list))
+
+	for _, item := range list {
+		if !seen[item] {
+			seen[item] = true
+			unique = append(unique, item)
+		}
+	}
+
+	return unique
+}
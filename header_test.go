@@ -0,0 +1,106 @@
+package types_splitter_plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_cutHeader(t *testing.T) {
+	header := &sourceHeader{Prefix: "posts", Rules: []string{"queries[0]", "lock"}, Originals: []string{"queries.graphql", "types.graphql"}}
+
+	parsed, input := cutHeader(header.String() + "type Post {\n  id: ID!\n}\n")
+	if !reflect.DeepEqual(parsed, header) {
+		t.Errorf("expected header %+v, got %+v", header, parsed)
+	}
+	if input != "type Post {\n  id: ID!\n}\n" {
+		t.Errorf("unexpected input %q", input)
+	}
+
+	// comments written by users aren't headers
+	parsed, input = cutHeader("# posts\ntype Post {\n  id: ID!\n}\n")
+	if parsed != nil || input != "# posts\ntype Post {\n  id: ID!\n}\n" {
+		t.Errorf("unexpected header %+v in %q", parsed, input)
+	}
+}
+
+func Test_MutateConfig_Header(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, src := range getTestSources(t, false) {
+		if err := os.WriteFile(filepath.Join(dir, src.Name), []byte(src.Input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(t *testing.T, cfg string) {
+		t.Helper()
+
+		splitterCfg := getTestConfig(t, cfg)
+		splitterCfg.Header = true
+		splitterCfg.WriteSources = true
+
+		splitter := &TypesSplitterPlugin{cfg: splitterCfg}
+		if err := splitter.MutateConfig(loadGenConfigFromDir(t, dir)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readFile := func(t *testing.T, name string) string {
+		t.Helper()
+
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	queries := filepath.Join(dir, "queries.graphql")
+
+	cfg := `
+types_splitter:
+  queries:
+    - prefix: posts
+      matches:
+        - post
+`
+	run(t, cfg)
+
+	postsQueries := readFile(t, "posts.queries.graphql")
+	expected := headerMarker + "\n# prefix: posts\n# rules: queries[0]\n# originals: " + queries + "\n\nextend type Query {\n"
+	if !strings.HasPrefix(postsQueries, expected) {
+		t.Fatalf("unexpected posts.queries.graphql:\n%s", postsQueries)
+	}
+
+	// the header is recognised when the output is read again
+	run(t, cfg)
+	if actual := readFile(t, "posts.queries.graphql"); actual != postsQueries {
+		t.Errorf("expected posts.queries.graphql to be unchanged, got:\n%s", actual)
+	}
+
+	// fields moved out of a split source are traced back to its originals, and emptied split sources
+	// are deleted despite their header
+	run(t, `
+types_splitter:
+  queries:
+    - prefix: blog
+      matches:
+        - post
+    - prefix: posts
+      matches:
+        - postsByNobody
+`)
+
+	if _, err := os.Stat(filepath.Join(dir, "posts.queries.graphql")); !os.IsNotExist(err) {
+		t.Errorf("expected posts.queries.graphql to be deleted, got %v", err)
+	}
+
+	blogQueries := readFile(t, "blog.queries.graphql")
+	expected = headerMarker + "\n# prefix: blog\n# rules: queries[0]\n# originals: " + queries + "\n\nextend type Query {\n"
+	if !strings.HasPrefix(blogQueries, expected) {
+		t.Errorf("unexpected blog.queries.graphql:\n%s", blogQueries)
+	}
+}
@@ -0,0 +1,495 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"fmt"
+	goast "go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/99designs/gqlgen/codegen/templates"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/imports"
+)
+
+// notImplemented is the start of the body gqlgen generates for resolvers without implementation.
+const notImplemented = `panic(fmt.Errorf("not implemented: `
+
+// notImplementedImport is the import used by the body gqlgen generates for resolvers without implementation.
+var notImplementedImport = &goast.ImportSpec{Path: &goast.BasicLit{Kind: token.STRING, Value: strconv.Quote("fmt")}}
+
+// gqlgenWarning and gqlgenWarningEnd are the first line and the end of the last line of the warning gqlgen adds above
+// the code it was going to delete from a resolver file, eg. helpers.
+const (
+	gqlgenWarning    = "// !!! WARNING !!!"
+	gqlgenWarningEnd = "You have helper methods in this file."
+)
+
+// majorVersion matches the major version suffix of an import path, eg. /v2 or .v3 for gopkg.in paths.
+var majorVersion = regexp.MustCompile(`[/.]v[0-9]+$`)
+
+// goFile is a parsed Go file of the resolver package.
+type goFile struct {
+	path string
+	src  []byte
+	fset *token.FileSet
+	file *goast.File
+}
+
+// loadGoFiles parses the Go files of the given directory, test files excluded.
+func loadGoFiles(dir string) (map[string]*goFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resolver files: %w", err)
+	}
+
+	files := make(map[string]*goFile, len(paths))
+	for _, p := range paths {
+		if strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read resolver file: %w", err)
+		}
+
+		f, err := parseGoFile(p, src)
+		if err != nil {
+			return nil, err
+		}
+		files[p] = f
+	}
+
+	return files, nil
+}
+
+// parseGoFile parses the given Go source.
+func parseGoFile(p string, src []byte) (*goFile, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, p, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse resolver file: %w", err)
+	}
+
+	return &goFile{path: p, src: src, fset: fset, file: file}, nil
+}
+
+// source returns the source of the given node, with its doc comment when it's a declaration.
+func (f *goFile) source(node goast.Node) string {
+	start, end := f.span(node)
+	return string(f.src[start:end])
+}
+
+// span returns the offsets of the given node, with its doc comment when it's a declaration.
+func (f *goFile) span(node goast.Node) (int, int) {
+	start := node.Pos()
+	switch decl := node.(type) {
+	case *goast.FuncDecl:
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+	case *goast.GenDecl:
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+	}
+
+	return f.fset.Position(start).Offset, f.fset.Position(node.End()).Offset
+}
+
+// methods returns the methods declared in the file by receiver type and name, eg. queryResolver.GetUser.
+func (f *goFile) methods() map[string]*goast.FuncDecl {
+	methods := make(map[string]*goast.FuncDecl)
+	for _, decl := range f.file.Decls {
+		if fn, ok := decl.(*goast.FuncDecl); ok {
+			if recv := receiverName(fn); recv != "" {
+				methods[recv+"."+fn.Name.Name] = fn
+			}
+		}
+	}
+	return methods
+}
+
+// receiverName returns the name of the receiver type of a method, or an empty string for a function.
+func receiverName(fn *goast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*goast.StarExpr); ok {
+		recv = star.X
+	}
+	if ident, ok := recv.(*goast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// declarations returns the top-level declarations of the file by the names they declare: functions, types,
+// variables and constants. Methods are declarations of their receiver type, so that they follow it.
+func (f *goFile) declarations() map[string][]goast.Decl {
+	decls := make(map[string][]goast.Decl)
+	add := func(name string, decl goast.Decl) {
+		if name != "_" {
+			decls[name] = append(decls[name], decl)
+		}
+	}
+
+	for _, decl := range f.file.Decls {
+		switch d := decl.(type) {
+		case *goast.FuncDecl:
+			if recv := receiverName(d); recv != "" {
+				add(recv, d)
+			} else if d.Recv == nil {
+				add(d.Name.Name, d)
+			}
+		case *goast.GenDecl:
+			for _, spec := range d.Specs {
+				switch sp := spec.(type) {
+				case *goast.TypeSpec:
+					add(sp.Name.Name, d)
+				case *goast.ValueSpec:
+					for _, name := range sp.Names {
+						add(name.Name, d)
+					}
+				}
+			}
+		}
+	}
+
+	return decls
+}
+
+// helperSource returns the source of a declaration moved out of a resolver file, without the warning gqlgen adds
+// above the code it was going to delete.
+func (f *goFile) helperSource(decl goast.Decl) string {
+	start, end := f.span(decl)
+
+	var doc *goast.CommentGroup
+	switch d := decl.(type) {
+	case *goast.FuncDecl:
+		doc = d.Doc
+	case *goast.GenDecl:
+		doc = d.Doc
+	}
+
+	if doc != nil && doc.List[0].Text == gqlgenWarning {
+		start = f.fset.Position(decl.Pos()).Offset
+
+		// the warning ends with the reasons it happens, what follows is the doc of the declaration
+		for i, c := range doc.List {
+			if strings.Contains(c.Text, gqlgenWarningEnd) && i+1 < len(doc.List) {
+				start = f.fset.Position(doc.List[i+1].Pos()).Offset
+			}
+		}
+	}
+
+	return string(f.src[start:end])
+}
+
+// imports returns the imports of the file by the name they're used with.
+func (f *goFile) imports() map[string]*goast.ImportSpec {
+	imports := make(map[string]*goast.ImportSpec)
+	for _, spec := range f.file.Imports {
+		imports[importName(spec)] = spec
+	}
+	return imports
+}
+
+// importName returns the name an import is used with: its alias, or the last element of its path without
+// major version suffix.
+func importName(spec *goast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	return path.Base(majorVersion.ReplaceAllString(importPath, ""))
+}
+
+// identifiers returns the identifiers used in the given node.
+func identifiers(node goast.Node) map[string]bool {
+	idents := make(map[string]bool)
+	goast.Inspect(node, func(n goast.Node) bool {
+		if ident, ok := n.(*goast.Ident); ok {
+			idents[ident.Name] = true
+		}
+		return true
+	})
+	return idents
+}
+
+// packages returns the names of the packages used in the given node, as the left side of selector expressions.
+func packages(node goast.Node) map[string]bool {
+	pkgs := make(map[string]bool)
+	goast.Inspect(node, func(n goast.Node) bool {
+		if sel, ok := n.(*goast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*goast.Ident); ok {
+				pkgs[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return pkgs
+}
+
+// isStub returns whether the body of a resolver is the one gqlgen generates without implementation.
+func isStub(body string) bool {
+	return strings.HasPrefix(strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}")), notImplemented)
+}
+
+// fileEdits are the edits of a resolver file, applied at once.
+type fileEdits struct {
+	// replaces are the replacements of source ranges, by start offset
+	replaces map[int]replace
+	// appends are the sources appended to the file
+	appends []string
+	// imports are the imports the file needs
+	imports []*goast.ImportSpec
+	// removedImports are the imports the file may not use anymore
+	removedImports []*goast.ImportSpec
+}
+
+// replace is a replacement of a source range.
+type replace struct {
+	end  int
+	text string
+}
+
+// loadResolverFiles parses the resolver files before gqlgen generates them, so that the implementations of the
+// resolvers moved to another file can be found afterwards.
+func (s *TypesSplitterPlugin) loadResolverFiles() error {
+	resolver := s.genCfg.Resolver
+	if !s.cfg.MoveResolvers || !resolver.IsDefined() || resolver.Layout != config.LayoutFollowSchema {
+		return nil
+	}
+
+	var err error
+	s.resolverFiles, err = loadGoFiles(resolver.Dir())
+	return err
+}
+
+// moveImplementations moves the implementations of the resolvers that gqlgen generated in another file than before,
+// with the imports and the helper functions they use, and removes them from their previous file when gqlgen left
+// them there, eg. in an orphan resolver file.
+func (s *TypesSplitterPlugin) moveImplementations(data *codegen.Data) error {
+	if s.resolverFiles == nil {
+		return nil
+	}
+
+	current, err := loadGoFiles(data.Config.Resolver.Dir())
+	if err != nil {
+		return err
+	}
+
+	generated := generatedResolverFiles(data)
+	edits := make(map[string]*fileEdits)
+	editsOf := func(p string) *fileEdits {
+		if edits[p] == nil {
+			edits[p] = &fileEdits{replaces: make(map[int]replace)}
+		}
+		return edits[p]
+	}
+
+	// resolver methods of the generated files, by receiver type and name
+	newMethods := make(map[string]*goFile)
+	for _, p := range sortedKeys(current) {
+		if generated[p] {
+			for key := range current[p].methods() {
+				newMethods[key] = current[p]
+			}
+		}
+	}
+
+	// the resolver types, eg. queryResolver, are declared by gqlgen and never moved as helpers
+	resolverTypes := make(map[string]bool)
+	for _, o := range data.Objects {
+		resolverTypes[templates.LcFirst(o.Name)+templates.UcFirst(data.Config.Resolver.Type)] = true
+	}
+
+	movedHelpers := make(map[goast.Decl]bool)
+	for _, oldPath := range sortedKeys(s.resolverFiles) {
+		oldFile := s.resolverFiles[oldPath]
+		oldImports := oldFile.imports()
+
+		for _, key := range sortedKeys(oldFile.methods()) {
+			newFile := newMethods[key]
+			if newFile == nil || newFile.path == oldPath {
+				continue
+			}
+
+			oldMethod := oldFile.methods()[key]
+			newMethod := newFile.methods()[key]
+			newEdits := editsOf(newFile.path)
+
+			// gqlgen copies the implementations it finds, only stubs are replaced
+			if isStub(newFile.source(newMethod.Body)) {
+				start, end := newFile.span(newMethod.Body)
+				newEdits.replaces[start] = replace{end: end, text: oldFile.source(oldMethod.Body)}
+				newEdits.removedImports = append(newEdits.removedImports, notImplementedImport)
+			}
+
+			// the method and the helpers it uses, found in the previous file once generated
+			// the receiver is left out, the resolver type isn't a helper of its methods
+			used, usedPackages := identifiers(oldMethod.Body), packages(oldMethod)
+			for ident := range identifiers(oldMethod.Type) {
+				used[ident] = true
+			}
+			if currentOld := current[oldPath]; currentOld != nil {
+				if method := currentOld.methods()[key]; method != nil {
+					start, end := currentOld.span(method)
+					editsOf(oldPath).replaces[start] = replace{end: end}
+				}
+
+				for _, helper := range helpersOf(currentOld, used, resolverTypes) {
+					if movedHelpers[helper] {
+						continue
+					}
+					movedHelpers[helper] = true
+
+					start, end := currentOld.span(helper)
+					editsOf(oldPath).replaces[start] = replace{end: end}
+					newEdits.appends = append(newEdits.appends, currentOld.helperSource(helper))
+					for pkg := range packages(helper) {
+						usedPackages[pkg] = true
+					}
+				}
+			}
+
+			// the imports used by the method and its helpers
+			for _, name := range sortedKeys(usedPackages) {
+				if spec := oldImports[name]; spec != nil {
+					newEdits.imports = append(newEdits.imports, spec)
+					editsOf(oldPath).removedImports = append(editsOf(oldPath).removedImports, spec)
+				}
+			}
+		}
+	}
+
+	for _, p := range sortedKeys(edits) {
+		if current[p] == nil {
+			continue
+		}
+		if err = edits[p].apply(current[p]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// helpersOf returns the declarations of the file used by the given identifiers, and by the declarations they use,
+// in the order of the file: functions, types with their methods, variables and constants. The given resolver types
+// are left out.
+func helpersOf(f *goFile, used map[string]bool, resolverTypes map[string]bool) []goast.Decl {
+	decls := f.declarations()
+	found := make(map[goast.Decl]bool)
+
+	var visit func(idents map[string]bool)
+	visit = func(idents map[string]bool) {
+		for ident := range idents {
+			if resolverTypes[ident] {
+				continue
+			}
+			for _, decl := range decls[ident] {
+				if !found[decl] {
+					found[decl] = true
+					visit(identifiers(decl))
+				}
+			}
+		}
+	}
+	visit(used)
+
+	var helpers []goast.Decl
+	for _, decl := range f.file.Decls {
+		if found[decl] {
+			helpers = append(helpers, decl)
+		}
+	}
+
+	return helpers
+}
+
+// apply applies the edits to the file, adds the imports it needs and removes the ones it doesn't use anymore,
+// then writes it formatted.
+func (e *fileEdits) apply(f *goFile) error {
+	starts := make([]int, 0, len(e.replaces))
+	for start := range e.replaces {
+		starts = append(starts, start)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(starts)))
+
+	src := append([]byte(nil), f.src...)
+	for _, start := range starts {
+		r := e.replaces[start]
+		src = append(src[:start], append([]byte(r.text), src[r.end:]...)...)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimRight(src, "\n"))
+	buf.WriteString("\n")
+	for _, helper := range e.appends {
+		buf.WriteString("\n" + helper + "\n")
+	}
+
+	return writeGoFile(f.path, buf.Bytes(), e.imports, e.removedImports)
+}
+
+// writeGoFile adds the given imports to the Go source and removes the given ones it doesn't use, then writes it
+// formatted.
+func writeGoFile(p string, src []byte, addedImports, removedImports []*goast.ImportSpec) error {
+	edited, err := parseGoFile(p, src)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range addedImports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			astutil.AddNamedImport(edited.fset, edited.file, spec.Name.Name, importPath)
+		} else {
+			astutil.AddImport(edited.fset, edited.file, importPath)
+		}
+	}
+
+	for _, spec := range removedImports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if !packages(edited.file)[importName(spec)] {
+			if spec.Name != nil {
+				astutil.DeleteNamedImport(edited.fset, edited.file, spec.Name.Name, importPath)
+			} else {
+				astutil.DeleteImport(edited.fset, edited.file, importPath)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = format.Node(&buf, edited.fset, edited.file); err != nil {
+		return fmt.Errorf("failed to format %s: %w", p, err)
+	}
+
+	// imports are grouped the way gqlgen formats the files it generates
+	src, err = imports.Process(p, buf.Bytes(), &imports.Options{FormatOnly: true, Comments: true, TabIndent: true, TabWidth: 8})
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", p, err)
+	}
+
+	if err = os.WriteFile(p, src, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+package types_splitter_plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func Test_GenerateCode_MoveImplementations(t *testing.T) {
+	dir := t.TempDir()
+	notice := resolverNotice + ", any resolver implementations\n"
+
+	// getUser was implemented in queries.resolvers.go before queries.graphql was emptied by the split
+	before := map[string]string{
+		"queries.resolvers.go": `package graph
+
+` + notice + `
+import (
+	"context"
+	"fmt"
+
+	db "example.com/app/db/v2"
+)
+
+// GetUser is the resolver for the getUser field.
+func (r *queryResolver) GetUser(ctx context.Context, id string) (*User, error) {
+	return findUser(ctx, id)
+}
+
+func findUser(ctx context.Context, id string) (*User, error) {
+	return db.Find(ctx, userKey(id))
+}
+
+func userKey(id string) string {
+	return fmt.Sprintf("user:%s", id)
+}
+`,
+	}
+
+	// gqlgen then generates a stub in users.queries.resolvers.go, and leaves queries.resolvers.go in place
+	after := map[string]string{
+		"users.queries.resolvers.go": `package graph
+
+` + notice + `
+import (
+	"context"
+	"fmt"
+)
+
+// GetUser is the resolver for the getUser field.
+func (r *queryResolver) GetUser(ctx context.Context, id string) (*User, error) {
+	panic(fmt.Errorf("not implemented: GetUser - getUser"))
+}
+`,
+	}
+
+	for name, content := range before {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := getTestConfig(t, `
+types_splitter:
+  move_resolvers: true
+  types:
+    - name: User
+      prefix: users
+`)
+
+	resolver := config.ResolverConfig{
+		Layout:           config.LayoutFollowSchema,
+		DirName:          dir,
+		Filename:         filepath.Join(dir, "resolver.go"),
+		FilenameTemplate: "{name}.resolvers.go",
+	}
+
+	splitter := &TypesSplitterPlugin{cfg: cfg, genCfg: &config.Config{Resolver: resolver}}
+	if err := splitter.loadResolverFiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range after {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := &ast.Source{Name: "users.queries.graphql"}
+	data := &codegen.Data{
+		Config: &config.Config{Resolver: resolver},
+		Objects: codegen.Objects{{
+			Definition: &ast.Definition{Name: "Query", Position: &ast.Position{Src: src}},
+			Fields: []*codegen.Field{{
+				FieldDefinition: &ast.FieldDefinition{Name: "getUser", Position: &ast.Position{Src: src}},
+				IsResolver:      true,
+			}},
+		}},
+	}
+
+	if err := splitter.GenerateCode(data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"users.queries.resolvers.go": `package graph
+
+` + notice + `
+import (
+	"context"
+	"fmt"
+
+	db "example.com/app/db/v2"
+)
+
+// GetUser is the resolver for the getUser field.
+func (r *queryResolver) GetUser(ctx context.Context, id string) (*User, error) {
+	return findUser(ctx, id)
+}
+
+func findUser(ctx context.Context, id string) (*User, error) {
+	return db.Find(ctx, userKey(id))
+}
+
+func userKey(id string) string {
+	return fmt.Sprintf("user:%s", id)
+}
+`,
+		// the orphan is left without the moved code, and reported
+		"queries.resolvers.go": "package graph\n\n" + notice,
+	}
+
+	for name, content := range expected {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("expected %s:\n%s\ngot:\n%s", name, content, b)
+		}
+	}
+
+	if warnings := strings.Join(splitter.Warnings(), "\n"); !strings.Contains(warnings, "queries.resolvers.go isn't generated from any source anymore") {
+		t.Errorf("expected an orphan warning, got %q", warnings)
+	}
+}
+
+func Test_GenerateCode_MoveImplementations_Declarations(t *testing.T) {
+	dir := t.TempDir()
+	notice := resolverNotice + ", any resolver implementations\n"
+
+	// the warning gqlgen adds above the code it was going to delete from a resolver file
+	warning := `// !!! WARNING !!!
+// The code below was going to be deleted when updating resolvers. It has been copied here so you have
+// one last chance to move it out of harms way if you want. There are two reasons this happens:
+//   - When renaming or deleting a resolver the old code will be put in here. You can safely delete
+//     it when you're done.
+//   - You have helper methods in this file. Move them out to keep these resolver files clean.
+`
+
+	// the helpers of getUser are types, variables and constants kept by gqlgen below its warning
+	helpers := `// userCache caches the users by id.
+type userCache map[string]*User
+
+func (c userCache) find(id string) (*User, error) {
+	if u, ok := c[id]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("%s: %w", id, errNotFound)
+}
+
+var users = userCache{}
+
+const notFound = "not found"
+
+var errNotFound = errors.New(notFound)
+`
+
+	queries := func(getUser string) string {
+		return `package graph
+
+` + notice + `
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Node is the resolver for the node field.
+func (r *queryResolver) Node(ctx context.Context, id string) (Node, error) {
+	panic(fmt.Errorf("not implemented: Node - node"))
+}
+` + getUser + `
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type queryResolver struct{ *Resolver }
+
+` + warning + helpers
+	}
+
+	getUser := `
+// GetUser is the resolver for the getUser field.
+func (r *queryResolver) GetUser(ctx context.Context, id string) (*User, error) {
+	return users.find(id)
+}
+`
+
+	if err := os.WriteFile(filepath.Join(dir, "queries.resolvers.go"), []byte(queries(getUser)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := getTestConfig(t, `
+types_splitter:
+  move_resolvers: true
+  queries:
+    - prefix: users
+      matches: [getUser]
+`)
+
+	resolver := config.ResolverConfig{
+		Layout:           config.LayoutFollowSchema,
+		DirName:          dir,
+		Filename:         filepath.Join(dir, "resolver.go"),
+		FilenameTemplate: "{name}.resolvers.go",
+		Type:             "Resolver",
+	}
+
+	splitter := &TypesSplitterPlugin{cfg: cfg, genCfg: &config.Config{Resolver: resolver}}
+	if err := splitter.loadResolverFiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	// gqlgen copies the implementation of getUser to its new file, and keeps the helpers below its warning
+	after := map[string]string{
+		"users.queries.resolvers.go": `package graph
+
+` + notice + `
+import (
+	"context"
+)
+` + getUser,
+		"queries.resolvers.go": queries(""),
+	}
+	for name, content := range after {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	queriesSrc := &ast.Source{Name: "queries.graphql"}
+	usersSrc := &ast.Source{Name: "users.queries.graphql"}
+	data := &codegen.Data{
+		Config: &config.Config{Resolver: resolver},
+		Objects: codegen.Objects{{
+			Definition: &ast.Definition{Name: "Query", Position: &ast.Position{Src: queriesSrc}},
+			Fields: []*codegen.Field{
+				{FieldDefinition: &ast.FieldDefinition{Name: "node", Position: &ast.Position{Src: queriesSrc}}, IsResolver: true},
+				{FieldDefinition: &ast.FieldDefinition{Name: "getUser", Position: &ast.Position{Src: usersSrc}}, IsResolver: true},
+			},
+		}},
+	}
+
+	if err := splitter.GenerateCode(data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"users.queries.resolvers.go": `package graph
+
+` + notice + `
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+` + getUser + "\n" + helpers,
+		// the resolver type is left in the file of Query
+		"queries.resolvers.go": `package graph
+
+` + notice + `
+import (
+	"context"
+	"fmt"
+)
+
+// Node is the resolver for the node field.
+func (r *queryResolver) Node(ctx context.Context, id string) (Node, error) {
+	panic(fmt.Errorf("not implemented: Node - node"))
+}
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type queryResolver struct{ *Resolver }
+`,
+	}
+
+	for name, content := range expected {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("expected %s:\n%s\ngot:\n%s", name, content, b)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const lockHeader = `# Code generated by types_splitter, DO NOT EDIT.
+# Placements are kept across runs, relock to update them from the rules.
+`
+
+// placementLock is the content of the lock file.
+type placementLock struct {
+	// Placements is a map of root fields (eg. Query.getUser) and types to the prefix they were split into,
+	// or an empty string if they were left in their original source.
+	Placements map[string]string `yaml:"placements"`
+}
+
+// loadLock reads the lock file, if any. The lock is ignored when relocking.
+func (s *TypesSplitterPlugin) loadLock() error {
+	s.locked = nil
+	if s.cfg.Lock == "" || s.relock {
+		return nil
+	}
+
+	b, err := os.ReadFile(s.cfg.path(s.cfg.Lock))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read lock file: %w", err)
+	}
+
+	lock := placementLock{}
+	if err = yaml.Unmarshal(b, &lock); err != nil {
+		return fmt.Errorf("unable to parse lock file: %w", err)
+	}
+
+	// the lock can be edited by hand, its prefixes are validated like the ones of the rules
+	var errs []string
+	for _, key := range sortedKeys(lock.Placements) {
+		if prefix := lock.Placements[key]; prefix != "" {
+			if err = validatePrefix(prefix); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", key, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid lock file:\n  %s", strings.Join(errs, "\n  "))
+	}
+
+	s.locked = lock.Placements
+
+	return nil
+}
+
+// writeLock writes the placements of the last run, and the locked ones it didn't place, to the lock file, if it
+// changed.
+func (s *TypesSplitterPlugin) writeLock() error {
+	if s.cfg.Lock == "" {
+		return nil
+	}
+
+	// locked placements are kept even when they weren't placed by this run, eg. a type removed from the schema
+	// for a while, until the next relock
+	placements := make(map[string]string, len(s.locked)+len(s.placements))
+	for key, prefix := range s.locked {
+		placements[key] = prefix
+	}
+	for key, prefix := range s.placements {
+		placements[key] = prefix
+	}
+
+	b, err := yaml.Marshal(placementLock{Placements: placements})
+	if err != nil {
+		return fmt.Errorf("unable to write lock file: %w", err)
+	}
+	b = append([]byte(lockHeader), b...)
+
+	path := s.cfg.path(s.cfg.Lock)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, b) {
+		return nil
+	}
+
+	if err = os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("unable to write lock file: %w", err)
+	}
+
+	return nil
+}
+
+// resolvePlacement returns the prefix of the root field or type with the given key, and keeps track of it.
+// Locked placements win over the rules, in which case disagreements with the rules are reported.
+func (s *TypesSplitterPlugin) resolvePlacement(key, rulePrefix string) string {
+	prefix := rulePrefix
+
+	if lockedPrefix, ok := s.locked[key]; ok {
+		if lockedPrefix != rulePrefix {
+			s.warnf("%s is locked in %s but the rules place it in %s, relock to move it", key, quotePrefix(lockedPrefix), quotePrefix(rulePrefix))
+		}
+		prefix = lockedPrefix
+	}
+
+	s.placements[key] = prefix
+
+	return prefix
+}
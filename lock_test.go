@@ -0,0 +1,126 @@
+package types_splitter_plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_MutateConfig_Lock(t *testing.T) {
+	dir := t.TempDir()
+
+	run := func(t *testing.T, cfg string, opts ...Option) (*TypesSplitterPlugin, []string) {
+		t.Helper()
+
+		splitterCfg := getTestConfig(t, cfg)
+		splitterCfg.dir = dir
+
+		splitter := &TypesSplitterPlugin{cfg: splitterCfg}
+		for _, opt := range opts {
+			opt(splitter)
+		}
+
+		genCfg := getTestGenConfig(t)
+		if err := splitter.MutateConfig(genCfg); err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, src := range genCfg.Sources {
+			names = append(names, src.Name)
+		}
+
+		return splitter, names
+	}
+
+	_, names := run(t, `
+types_splitter:
+  lock: types_splitter.lock
+  queries:
+    - prefix: posts
+      matches:
+        - post
+`)
+	if strings.Join(names, ",") != "directives.graphql,enums.graphql,interfaces.graphql,mutations.graphql,posts.graphql,posts.mutations.graphql,posts.queries.graphql,queries.graphql,users.graphql" {
+		t.Errorf("unexpected sources %s", names)
+	}
+
+	lock, err := os.ReadFile(filepath.Join(dir, "types_splitter.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(lock), "Query.getPost: posts\n") || !strings.Contains(string(lock), `Query.getUser: ""`) {
+		t.Errorf("unexpected lock file:\n%s", lock)
+	}
+
+	// the new rule only applies to fields that aren't locked
+	cfg := `
+types_splitter:
+  lock: types_splitter.lock
+  queries:
+    - prefix: users
+      matches:
+        - user|getPost$
+`
+	splitter, names := run(t, cfg)
+	if strings.Join(names, ",") != "directives.graphql,enums.graphql,interfaces.graphql,mutations.graphql,posts.graphql,posts.mutations.graphql,posts.queries.graphql,queries.graphql,users.graphql" {
+		t.Errorf("unexpected sources %s", names)
+	}
+
+	warnings := strings.Join(splitter.Warnings(), "\n")
+	if !strings.Contains(warnings, `Query.getPost is locked in posts but the rules place it in users, relock to move it`) ||
+		!strings.Contains(warnings, `Query.getUser is locked in "" but the rules place it in users, relock to move it`) {
+		t.Errorf("unexpected warnings:\n%s", warnings)
+	}
+
+	// relocking places everything with the rules
+	_, names = run(t, cfg+`
+  types:
+    - name: Manager
+      prefix: managers.users
+`, WithRelock())
+	if strings.Join(names, ",") != "directives.graphql,enums.graphql,interfaces.graphql,managers.users.graphql,mutations.graphql,posts.graphql,queries.graphql,users.graphql,users.mutations.graphql,users.queries.graphql" {
+		t.Errorf("unexpected sources %s", names)
+	}
+
+	lock, err = os.ReadFile(filepath.Join(dir, "types_splitter.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(lock), "Query.getPost: users\n") || !strings.Contains(string(lock), "Query.getUser: users\n") {
+		t.Errorf("unexpected lock file:\n%s", lock)
+	}
+
+	// locked placements are kept without the type rules, along with the ones that aren't placed anymore
+	lock = append(lock, "    Query.getLegacy: legacy\n"...)
+	if err = os.WriteFile(filepath.Join(dir, "types_splitter.lock"), lock, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, names = run(t, cfg)
+	if strings.Join(names, ",") != "directives.graphql,enums.graphql,interfaces.graphql,managers.users.graphql,mutations.graphql,posts.graphql,queries.graphql,users.graphql,users.mutations.graphql,users.queries.graphql" {
+		t.Errorf("unexpected sources %s", names)
+	}
+
+	lock, err = os.ReadFile(filepath.Join(dir, "types_splitter.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(lock), "Manager: managers.users\n") || !strings.Contains(string(lock), "Query.getLegacy: legacy\n") {
+		t.Errorf("unexpected lock file:\n%s", lock)
+	}
+
+	// the prefixes of the lock are validated
+	lock = append(lock, "    Query.getAdmin: ../admins\n"...)
+	if err = os.WriteFile(filepath.Join(dir, "types_splitter.lock"), lock, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	splitterCfg := getTestConfig(t, cfg)
+	splitterCfg.dir = dir
+	err = (&TypesSplitterPlugin{cfg: splitterCfg}).MutateConfig(getTestGenConfig(t))
+	if err == nil || !strings.Contains(err.Error(), "invalid lock file:\n  Query.getAdmin: prefix \"../admins\" must not start or end with a dot") {
+		t.Errorf("expected the lock file to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package types_splitter_plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// lockRule is the rule of placements that were kept from the lock file.
+const lockRule = "lock"
+
+// Manifest describes the changes made by the split.
+type Manifest struct {
+	// Moves is the list of definitions and fields moved to another source, sorted by new source and line.
+	Moves []*ManifestMove `json:"moves" yaml:"moves"`
+	// Created is the list of sources created by the split.
+	Created []string `json:"created" yaml:"created"`
+	// Deleted is the list of original sources deleted by the split.
+	Deleted []string `json:"deleted" yaml:"deleted"`
+}
+
+// ManifestMove is a definition or a field moved to another source.
+type ManifestMove struct {
+	// Kind is the kind of the moved definition or field: query, mutation, subscription or type.
+	Kind string `json:"kind" yaml:"kind"`
+	// Name is the name of the type, or the name of the root field prefixed with its type, eg. Query.getUser.
+	Name string `json:"name" yaml:"name"`
+	// Prefix is the prefix the definition or field was split into.
+	Prefix string `json:"prefix" yaml:"prefix"`
+	// Rule is the config that matched, eg. queries[1] or types[0], or lock if the placement comes from the lock file.
+	Rule string `json:"rule" yaml:"rule"`
+	// Match is the regex of the query config that matched, if any.
+	Match string `json:"match,omitempty" yaml:"match,omitempty"`
+	// OriginalSource is the name of the source the definition or field was moved from.
+	OriginalSource string `json:"original_source" yaml:"original_source"`
+	// OriginalLine is the line of the definition or field in its original source.
+	OriginalLine int `json:"original_line" yaml:"original_line"`
+	// Source is the name of the source the definition or field was moved to.
+	Source string `json:"source" yaml:"source"`
+	// Line is the line of the definition or field in its new source.
+	Line int `json:"line" yaml:"line"`
+
+	// pos is the position of the moved definition or field, which is final once the new sources are generated
+	pos *ast.Position
+}
+
+// Manifest returns the manifest of the last split.
+func (s *TypesSplitterPlugin) Manifest() *Manifest {
+	return s.manifest
+}
+
+// recordFieldMove keeps track of a root field moved to a new source.
+func (s *TypesSplitterPlugin) recordFieldMove(newSrc *Source, rootDef *ast.Definition, field *FieldDefinition, prefix string) {
+	move := &ManifestMove{
+		Kind:           fieldKind(field.typ),
+		Name:           fieldPlacementKey(rootDef, field),
+		Prefix:         prefix,
+		Rule:           lockRule,
+		OriginalSource: field.OriginalPosition.Src.Name,
+		OriginalLine:   field.OriginalPosition.Line,
+		pos:            field.Position,
+	}
+
+	if qi := s.cfg.QueryConfig.findRuleIndex(field.Name); qi >= 0 && s.cfg.QueryConfig[qi].ResolverPrefix == prefix {
+		move.Rule = fmt.Sprintf("queries[%d]", qi)
+		move.Match = s.cfg.QueryConfig[qi].matchingRegex(field.Name)
+	}
+
+	s.moves = append(s.moves, move)
+	newSrc.moves = append(newSrc.moves, move)
+}
+
+// recordTypeMove keeps track of a type moved to a new source.
+func (s *TypesSplitterPlugin) recordTypeMove(newSrc *Source, def *Definition, prefix string) {
+	move := &ManifestMove{
+		Kind:           typeKind,
+		Name:           def.Name,
+		Prefix:         prefix,
+		Rule:           lockRule,
+		OriginalSource: def.OriginalPosition.Src.Name,
+		OriginalLine:   def.OriginalPosition.Line,
+		pos:            def.Position,
+	}
+
+	if ti := s.cfg.TypeConfig.findRuleIndex(def.Name); ti >= 0 && s.cfg.TypeConfig[ti].ResolverPrefix == prefix {
+		move.Rule = fmt.Sprintf("types[%d]", ti)
+	}
+
+	s.moves = append(s.moves, move)
+	newSrc.moves = append(newSrc.moves, move)
+}
+
+// buildManifest builds the manifest of the split once the sources of the config are final.
+func (s *TypesSplitterPlugin) buildManifest() *Manifest {
+	manifest := &Manifest{
+		Moves:   append([]*ManifestMove{}, s.moves...),
+		Created: []string{},
+		Deleted: []string{},
+	}
+
+	for _, move := range manifest.Moves {
+		move.Source = move.pos.Src.Name
+		move.Line = move.pos.Line
+	}
+
+	sort.SliceStable(manifest.Moves, func(i, j int) bool {
+		if manifest.Moves[i].Source != manifest.Moves[j].Source {
+			return manifest.Moves[i].Source < manifest.Moves[j].Source
+		}
+		return manifest.Moves[i].Line < manifest.Moves[j].Line
+	})
+
+	for _, src := range s.genCfg.Sources {
+		if _, ok := s.sources[src.Name]; !ok {
+			manifest.Created = append(manifest.Created, src.Name)
+		}
+	}
+
+	for name, src := range s.sources {
+		if !containsSource(s.genCfg.Sources, src.Source) {
+			manifest.Deleted = append(manifest.Deleted, name)
+		}
+	}
+
+	sort.Strings(manifest.Created)
+	sort.Strings(manifest.Deleted)
+
+	return manifest
+}
+
+// writeManifest writes the manifest of the last split, as YAML if the path has a YAML extension,
+// and as JSON otherwise.
+func (s *TypesSplitterPlugin) writeManifest() error {
+	if s.cfg.Manifest == "" {
+		return nil
+	}
+
+	var b []byte
+	var err error
+
+	path := s.cfg.path(s.cfg.Manifest)
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		b, err = yaml.Marshal(s.manifest)
+	default:
+		b, err = json.MarshalIndent(s.manifest, "", "  ")
+		b = append(b, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write manifest: %w", err)
+	}
+
+	if err = os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("unable to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// fieldKind returns the kind of a root field in the manifest.
+func fieldKind(typ FieldDefType) string {
+	switch typ {
+	case DefQueryField:
+		return "query"
+	case DefMutationField:
+		return "mutation"
+	case DefSubscriptionField:
+		return "subscription"
+	default:
+		return "field"
+	}
+}
@@ -0,0 +1,136 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigVersion is the current version of the config format.
+const ConfigVersion = 3
+
+// configMigrations is the list of migrations of the config format, where configMigrations[i]
+// migrates the root node of a config from version i+1 to version i+2.
+var configMigrations = []func(root *yaml.Node) error{
+	migrateV1ToV2,
+	migrateV2ToV3,
+}
+
+// MigrateConfig reads a config in any supported version and writes it in the current format,
+// keeping its comments. If the config is already in the current format, it's written unchanged.
+// It returns whether the config was migrated.
+func MigrateConfig(in io.Reader, out io.Writer) (bool, error) {
+	b, err := io.ReadAll(in)
+	if err != nil {
+		return false, fmt.Errorf("unable to read config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err = yaml.Unmarshal(b, &doc); err != nil {
+		return false, fmt.Errorf("unable to parse config: %w", err)
+	}
+
+	if len(doc.Content) == 0 {
+		return false, fmt.Errorf("empty config")
+	}
+
+	migrated, err := migrateConfigNode(doc.Content[0])
+	if err != nil {
+		return false, err
+	}
+
+	if !migrated {
+		_, err = out.Write(b)
+		return false, err
+	}
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+
+	if err = enc.Encode(&doc); err != nil {
+		return false, fmt.Errorf("unable to write config: %w", err)
+	}
+
+	if _, err = out.Write(buf.Bytes()); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// migrateConfigNode migrates the root node of a config to the current version.
+// It returns whether any migration was applied.
+func migrateConfigNode(root *yaml.Node) (bool, error) {
+	if root.Kind != yaml.MappingNode {
+		return false, fmt.Errorf("unable to parse config: expected a mapping")
+	}
+
+	version, err := configNodeVersion(root)
+	if err != nil {
+		return false, err
+	}
+
+	if version > ConfigVersion {
+		return false, fmt.Errorf("config version %d is not supported, upgrade the plugin to use it (max version: %d)", version, ConfigVersion)
+	}
+
+	for v := version; v < ConfigVersion; v++ {
+		if err = configMigrations[v-1](root); err != nil {
+			return false, fmt.Errorf("unable to migrate config from version %d to %d: %w", v, v+1, err)
+		}
+	}
+
+	return version < ConfigVersion, nil
+}
+
+// configNodeVersion returns the version of the config, configs without version being version 1.
+func configNodeVersion(root *yaml.Node) (int, error) {
+	node := mappingValue(root, "version")
+	if node == nil {
+		return 1, nil
+	}
+
+	version, err := strconv.Atoi(node.Value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid config version %q", node.Value)
+	}
+
+	return version, nil
+}
+
+// migrateV1ToV2 adds the version to the config, which is unversioned in version 1.
+func migrateV1ToV2(root *yaml.Node) error {
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "2"}
+
+	// keep the comments at the top of the file above the version
+	if len(root.Content) > 0 {
+		key.HeadComment = root.Content[0].HeadComment
+		root.Content[0].HeadComment = ""
+	}
+
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+
+	return nil
+}
+
+// migrateV2ToV3 keeps the resolver layout forced to follow-schema, which versions 1 and 2 did implicitly.
+func migrateV2ToV3(root *yaml.Node) error {
+	mappingValue(root, "version").Value = "3"
+
+	splitter := mappingValue(root, "types_splitter")
+	if splitter == nil || splitter.Kind != yaml.MappingNode || mappingValue(splitter, "resolver_layout") != nil {
+		return nil
+	}
+
+	splitter.Content = append(splitter.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "resolver_layout"},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "follow-schema"},
+	)
+
+	return nil
+}
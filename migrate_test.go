@@ -0,0 +1,91 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_MigrateConfig(t *testing.T) {
+	v1 := `# yaml-language-server: $schema=types_splitter.schema.json
+types_splitter:
+  types:
+    # users are split into their own file
+    - name: User
+      prefix: users
+  queries:
+    - prefix: users
+      matches:
+        - user # getUser, createUser...
+`
+
+	expected := `# yaml-language-server: $schema=types_splitter.schema.json
+version: 3
+types_splitter:
+  types:
+    # users are split into their own file
+    - name: User
+      prefix: users
+  queries:
+    - prefix: users
+      matches:
+        - user # getUser, createUser...
+  resolver_layout: follow-schema
+`
+
+	out := &bytes.Buffer{}
+	migrated, err := MigrateConfig(strings.NewReader(v1), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !migrated {
+		t.Error("expected config to be migrated")
+	}
+	if out.String() != expected {
+		t.Errorf("expected migrated config:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	// version 2 only gets the resolver layout it forced
+	v2 := strings.Replace(v1, "types_splitter:", "version: 2\ntypes_splitter:", 1)
+	out.Reset()
+	if _, err = MigrateConfig(strings.NewReader(v2), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected migrated config:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	// the current version is written unchanged
+	out.Reset()
+	migrated, err = MigrateConfig(strings.NewReader(expected), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if migrated {
+		t.Error("expected config not to be migrated")
+	}
+	if out.String() != expected {
+		t.Errorf("expected config to be unchanged, got:\n%s", out.String())
+	}
+
+	// all the versions are understood by readConfig, older ones keeping the resolver layout they forced
+	for _, cfg := range []string{v1, v2, expected} {
+		splitterCfg, err := readConfig(strings.NewReader(cfg))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if prefix, _ := splitterCfg.QueryConfig.FindResolverPrefix("getUser"); prefix != "users" {
+			t.Errorf("expected getUser to match users, got %q", prefix)
+		}
+		if splitterCfg.ResolverLayout != "follow-schema" {
+			t.Errorf("expected the resolver layout follow-schema, got %q", splitterCfg.ResolverLayout)
+		}
+	}
+
+	if _, err = readConfig(strings.NewReader("version: 99\n" + v1)); err == nil {
+		t.Error("expected unsupported version to be rejected")
+	}
+}
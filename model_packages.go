@@ -0,0 +1,378 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/99designs/gqlgen/codegen/templates"
+)
+
+// modelPackage is the package of the models of a prefix under the model dir, or the model package itself.
+type modelPackage struct {
+	prefix string
+	// name is the name the package is referenced with by the other model packages
+	name       string
+	importPath string
+	dir        string
+	// decls are the sources of the declarations moved to the package, with their references qualified
+	decls []string
+	// imports are the prefixes of the model packages the package references
+	imports map[string]bool
+}
+
+// importSpec returns the import of the package, named when its name isn't the one it would be imported with.
+func (p *modelPackage) importSpec() *goast.ImportSpec {
+	spec := &goast.ImportSpec{Path: &goast.BasicLit{Kind: token.STRING, Value: strconv.Quote(p.importPath)}}
+	if importName(spec) != p.name {
+		spec.Name = goast.NewIdent(p.name)
+	}
+	return spec
+}
+
+// splitModelPackages moves the models of the split types from the models file to a package per prefix under the
+// model dir, eg. graph/model/users, and binds their types to it. The references between the model packages are
+// qualified, and an import cycle between them is reported with the references causing it.
+func (s *TypesSplitterPlugin) splitModelPackages(f *goFile, prefixes map[string]string) error {
+	model := s.genCfg.Model
+	root := &modelPackage{name: f.file.Name.Name, importPath: model.ImportPath(), dir: filepath.Dir(model.Filename), imports: make(map[string]bool)}
+	pkgs := map[string]*modelPackage{"": root}
+
+	goNames := make(map[string]string)
+	goPrefixes := make(map[string]string)
+	for name, prefix := range prefixes {
+		goNames[name] = templates.ToGo(name)
+		goPrefixes[templates.ToGo(name)] = prefix
+	}
+
+	// the types of the model package, which stay in it unless they're models of a split type
+	files, err := loadGoFiles(root.dir)
+	if err != nil {
+		return err
+	}
+	declared := make(map[string]string)
+	for _, file := range files {
+		for _, name := range declaredTypes(file.file) {
+			declared[name] = ""
+		}
+	}
+	for _, name := range declaredTypes(f.file) {
+		declared[name] = goPrefixes[name]
+	}
+
+	used := make(map[string]bool)
+	for _, prefix := range declared {
+		if prefix != "" {
+			used[prefix] = true
+		}
+	}
+
+	// the package of each prefix, named after its last part unless another package has the same name
+	names := map[string]bool{root.name: true}
+	for _, prefix := range sortedKeys(used) {
+		p := &modelPackage{
+			prefix:     prefix,
+			name:       packageName(prefix),
+			importPath: root.importPath + "/" + prefixDir(prefix),
+			dir:        filepath.Join(root.dir, prefixDir(prefix)),
+			imports:    make(map[string]bool),
+		}
+		if names[p.name] {
+			p.name = invalidPackageChars.ReplaceAllString(strings.ToLower(prefix), "_")
+		}
+		names[p.name] = true
+		pkgs[prefix] = p
+	}
+
+	// the declarations are moved to the package of their type, with the references to the types of other
+	// packages qualified
+	edits := &fileEdits{replaces: make(map[int]replace), removedImports: f.file.Imports}
+	refs := make(map[[2]string]map[string]bool)
+	for _, decl := range f.file.Decls {
+		// declarations of no type, eg. constants, stay in the model package
+		owner := declaredType(decl)
+		from := pkgs[declared[owner]]
+
+		inserts := make(map[int]string)
+		for _, ident := range typeReferences(decl) {
+			to, ok := declared[ident.Name]
+			if !ok || to == from.prefix {
+				continue
+			}
+
+			inserts[f.fset.Position(ident.Pos()).Offset] = pkgs[to].name + "."
+			from.imports[to] = true
+
+			edge := [2]string{from.prefix, to}
+			if refs[edge] == nil {
+				refs[edge] = make(map[string]bool)
+			}
+			refs[edge][fmt.Sprintf("%s.%s references %s.%s", from.name, owner, pkgs[to].name, ident.Name)] = true
+		}
+
+		start, end := f.span(decl)
+		if from == root {
+			for offset, qualifier := range inserts {
+				edits.replaces[offset] = replace{end: offset, text: qualifier}
+			}
+			continue
+		}
+
+		from.decls = append(from.decls, insertAt(f.src, start, end, inserts))
+		edits.replaces[start] = replace{end: end}
+	}
+
+	if err = checkImportCycles(pkgs, refs); err != nil {
+		return err
+	}
+
+	base := filepath.Base(model.Filename)
+	notice := fmt.Sprintf(splitNotice, base)
+	written := make(map[string]bool)
+	for _, prefix := range sortedKeys(pkgs) {
+		p := pkgs[prefix]
+		if p == root || len(p.decls) == 0 {
+			continue
+		}
+
+		if err = os.MkdirAll(p.dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create the model package of %s: %w", prefix, err)
+		}
+
+		buf := bytes.NewBufferString(splitHeader(f, notice, packageName(prefix)))
+		for _, decl := range p.decls {
+			buf.WriteString("\n" + decl + "\n")
+		}
+
+		filename := filepath.Join(p.dir, base)
+		if err = writeGoFile(filename, buf.Bytes(), p.importSpecs(pkgs), f.file.Imports); err != nil {
+			return err
+		}
+		written[filename] = true
+	}
+
+	edits.imports = root.importSpecs(pkgs)
+	if err = edits.apply(f); err != nil {
+		return err
+	}
+
+	s.bindModels(pkgs, declared, goNames, prefixes)
+
+	// files split by a previous run for prefixes without models anymore
+	err = filepath.WalkDir(root.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() != base || filepath.Dir(p) == root.dir || written[p] {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil || !bytes.Contains(content, []byte(notice)) {
+			return err
+		}
+		if err = os.Remove(p); err != nil {
+			return err
+		}
+		// the directory is only removed if it's now empty
+		_ = os.Remove(filepath.Dir(p))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete stale model packages: %w", err)
+	}
+
+	// the model packages changed since modelgen loaded them
+	if s.genCfg.Packages != nil {
+		for _, p := range pkgs {
+			s.genCfg.Packages.Evict(p.importPath)
+		}
+	}
+
+	return nil
+}
+
+// importSpecs returns the imports of the model packages the package references.
+func (p *modelPackage) importSpecs(pkgs map[string]*modelPackage) []*goast.ImportSpec {
+	specs := make([]*goast.ImportSpec, 0, len(p.imports))
+	for _, prefix := range sortedKeys(p.imports) {
+		specs = append(specs, pkgs[prefix].importSpec())
+	}
+	return specs
+}
+
+// bindModels binds the split types to the package of their prefix, replacing the binding modelgen added, and adds
+// the packages to the autobind list so that gqlgen loads them with the other bound packages.
+func (s *TypesSplitterPlugin) bindModels(pkgs map[string]*modelPackage, declared, goNames, prefixes map[string]string) {
+	if s.genCfg.Models == nil {
+		s.genCfg.Models = make(map[string]config.TypeMapEntry)
+	}
+
+	root := pkgs[""]
+	for _, name := range sortedKeys(prefixes) {
+		// the types that weren't generated in the models file keep their binding
+		p := pkgs[prefixes[name]]
+		if declared[goNames[name]] != prefixes[name] {
+			continue
+		}
+
+		generated := root.importPath + "." + goNames[name]
+		bound := p.importPath + "." + goNames[name]
+
+		entry := s.genCfg.Models[name]
+		models := make([]string, 0, len(entry.Model)+1)
+		for _, m := range entry.Model {
+			if m != generated {
+				models = append(models, m)
+			}
+		}
+		entry.Model = append([]string{bound}, models...)
+		s.genCfg.Models[name] = entry
+	}
+
+	for _, prefix := range sortedKeys(pkgs) {
+		p := pkgs[prefix]
+		if p == root || len(p.decls) == 0 {
+			continue
+		}
+
+		bound := false
+		for _, autobind := range s.genCfg.AutoBind {
+			bound = bound || autobind == p.importPath
+		}
+		if !bound {
+			s.genCfg.AutoBind = append(s.genCfg.AutoBind, p.importPath)
+		}
+	}
+}
+
+// checkImportCycles returns an error describing the first import cycle between the model packages, with the
+// references between the types causing it.
+func checkImportCycles(pkgs map[string]*modelPackage, refs map[[2]string]map[string]bool) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int)
+	var stack []string
+	var cycle []string
+
+	var visit func(prefix string) bool
+	visit = func(prefix string) bool {
+		state[prefix] = visiting
+		stack = append(stack, prefix)
+		for _, to := range sortedKeys(pkgs[prefix].imports) {
+			switch state[to] {
+			case visiting:
+				for i, p := range stack {
+					if p == to {
+						cycle = append(append([]string(nil), stack[i:]...), to)
+					}
+				}
+				return true
+			case unvisited:
+				if visit(to) {
+					return true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[prefix] = visited
+		return false
+	}
+
+	for _, prefix := range sortedKeys(pkgs) {
+		if state[prefix] == unvisited && visit(prefix) {
+			break
+		}
+	}
+	if cycle == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(cycle))
+	var causes []string
+	for i, prefix := range cycle {
+		names = append(names, pkgs[prefix].importPath)
+		if i > 0 {
+			causes = append(causes, sortedKeys(refs[[2]string{cycle[i-1], prefix}])...)
+		}
+	}
+
+	return fmt.Errorf("import cycle between the model packages %s:\n\t%s", strings.Join(names, " -> "), strings.Join(causes, "\n\t"))
+}
+
+// declaredTypes returns the names of the types declared in the file.
+func declaredTypes(file *goast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*goast.GenDecl); ok && gen.Tok == token.TYPE {
+			for _, spec := range gen.Specs {
+				names = append(names, spec.(*goast.TypeSpec).Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// typeReferences returns the identifiers of the declaration that may reference a type of the package, leaving out
+// the names it declares and the selected names.
+func typeReferences(decl goast.Decl) []*goast.Ident {
+	names := make(map[*goast.Ident]bool)
+	goast.Inspect(decl, func(n goast.Node) bool {
+		switch n := n.(type) {
+		case *goast.Field:
+			for _, name := range n.Names {
+				names[name] = true
+			}
+		case *goast.SelectorExpr:
+			names[n.Sel] = true
+		case *goast.FuncDecl:
+			names[n.Name] = true
+		case *goast.TypeSpec:
+			names[n.Name] = true
+		case *goast.ValueSpec:
+			for _, name := range n.Names {
+				names[name] = true
+			}
+		case *goast.KeyValueExpr:
+			if key, ok := n.Key.(*goast.Ident); ok {
+				names[key] = true
+			}
+		}
+		return true
+	})
+
+	var idents []*goast.Ident
+	goast.Inspect(decl, func(n goast.Node) bool {
+		if ident, ok := n.(*goast.Ident); ok && !names[ident] {
+			idents = append(idents, ident)
+		}
+		return true
+	})
+	return idents
+}
+
+// insertAt returns the source between the given offsets with the texts inserted at their offset.
+func insertAt(src []byte, start, end int, inserts map[int]string) string {
+	offsets := make([]int, 0, len(inserts))
+	for offset := range inserts {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+
+	var buf strings.Builder
+	for _, offset := range offsets {
+		buf.Write(src[start:offset])
+		buf.WriteString(inserts[offset])
+		start = offset
+	}
+	buf.Write(src[start:end])
+	return buf.String()
+}
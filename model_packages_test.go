@@ -0,0 +1,248 @@
+package types_splitter_plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func Test_MutateConfig_ModelPackages(t *testing.T) {
+	const models = `// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type Node interface {
+	IsNode()
+	GetID() string
+}
+
+type Post struct {
+	ID     string ` + "`json:\"id\"`" + `
+	Editor *User  ` + "`json:\"editor\"`" + `
+}
+
+func (Post) IsNode()            {}
+func (this Post) GetID() string { return this.ID }
+
+type User struct {
+	ID    string ` + "`json:\"id\"`" + `
+	Roles []Role ` + "`json:\"roles\"`" + `
+}
+
+type UserFilter struct {
+	Name *string ` + "`json:\"name,omitempty\"`" + `
+}
+
+type Role string
+
+const (
+	RoleAdmin Role = "ADMIN"
+)
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+	*e = Role(str)
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(string(e)))
+}
+`
+
+	const queries = `type Query {
+  findUsers(filter: UserFilter): [User!]!
+  getPost(id: ID!): Post
+}
+`
+
+	const types = `interface Node {
+  id: ID!
+}
+
+enum Role {
+  ADMIN
+}
+
+type Post implements Node {
+  id: ID!
+  editor: User!
+}
+
+type User {
+  id: ID!
+  roles: [Role!]!
+}
+
+input UserFilter {
+  name: String
+}
+`
+
+	tests := []struct {
+		name  string
+		rules string
+		// expected are the contents expected in the files of the model dir
+		expected map[string][]string
+		// bindings are the expected bindings of the schema types, relative to the module
+		bindings map[string]string
+		err      string
+	}{
+		{
+			name: "packages",
+			rules: `
+  types:
+    - name: User
+      prefix: users
+    - name: Post
+      prefix: blog.posts
+  queries:
+    - prefix: users
+      matches:
+        - user
+`,
+			expected: map[string][]string{
+				"models_gen.go": {"type Node interface", "type Role string"},
+				"users/models_gen.go": {
+					"// Split from models_gen.go by types_splitter.\n\npackage users\n",
+					`"example.com/app/graph/model"`,
+					"Roles []model.Role",
+					"type UserFilter struct",
+				},
+				"blog/posts/models_gen.go": {
+					"package posts\n",
+					`"example.com/app/graph/model/users"`,
+					"Editor *users.User",
+					"func (Post) IsNode()",
+				},
+			},
+			bindings: map[string]string{
+				"User":       "graph/model/users.User",
+				"UserFilter": "graph/model/users.UserFilter",
+				"Post":       "graph/model/blog/posts.Post",
+			},
+		},
+		{
+			name: "import cycle",
+			rules: `
+  types:
+    - name: User
+      prefix: users
+`,
+			err: "import cycle between the model packages example.com/app/graph/model -> example.com/app/graph/model/users -> example.com/app/graph/model:\n" +
+				"\tmodel.Post references users.User\n" +
+				"\tusers.User references model.Role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			modelDir := filepath.Join(dir, "graph", "model")
+
+			files := map[string]string{
+				"go.mod":                    "module example.com/app\n\ngo 1.20\n",
+				"graph/model/models_gen.go": models,
+				// split by a previous run for a prefix without models anymore
+				"graph/model/editors/models_gen.go": "// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.\n" +
+					"// Split from models_gen.go by types_splitter.\n\npackage editors\n",
+			}
+			for name, content := range files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			sources := []*ast.Source{{Name: "queries.graphql", Input: queries}, {Name: "types.graphql", Input: types}}
+			parsed, err := gqlparser.LoadSchema(sources...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			genCfg := &config.Config{
+				Sources: sources,
+				Schema:  parsed,
+				Model:   config.PackageConfig{Filename: filepath.Join(modelDir, "models_gen.go"), Package: "model"},
+				Models: config.TypeMap{
+					"User": {Model: []string{"example.com/app/graph/model.User"}},
+					"ID":   {Model: []string{"github.com/99designs/gqlgen/graphql.ID"}},
+				},
+			}
+
+			cfg := getTestConfig(t, "types_splitter:\n  model_packages: true"+tt.rules)
+			err = (&TypesSplitterPlugin{cfg: cfg}).MutateConfig(genCfg)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+
+				// nothing is moved
+				b, _ := os.ReadFile(filepath.Join(modelDir, "models_gen.go"))
+				if string(b) != models {
+					t.Errorf("expected the models file to be unchanged, got:\n%s", b)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, expected := range tt.expected {
+				b, err := os.ReadFile(filepath.Join(modelDir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, e := range expected {
+					if !strings.Contains(string(b), e) {
+						t.Errorf("expected %s to contain %q, got:\n%s", name, e, b)
+					}
+				}
+			}
+
+			for name, expected := range tt.bindings {
+				if bound := genCfg.Models[name].Model; !reflect.DeepEqual(bound, config.StringList{"example.com/app/" + expected}) {
+					t.Errorf("expected %s to be bound to %s, got %v", name, expected, bound)
+				}
+			}
+			if bound := genCfg.Models["ID"].Model; !reflect.DeepEqual(bound, config.StringList{"github.com/99designs/gqlgen/graphql.ID"}) {
+				t.Errorf("expected ID to keep its binding, got %v", bound)
+			}
+
+			autobind := []string{"example.com/app/graph/model/blog/posts", "example.com/app/graph/model/users"}
+			if !reflect.DeepEqual(genCfg.AutoBind, autobind) {
+				t.Errorf("expected autobind %v, got %v", autobind, genCfg.AutoBind)
+			}
+
+			if _, err = os.Stat(filepath.Join(modelDir, "editors")); !os.IsNotExist(err) {
+				t.Errorf("expected the stale editors package to be deleted, got %v", err)
+			}
+
+			if _, err := exec.LookPath("go"); err == nil {
+				cmd := exec.Command("go", "build", "./...")
+				cmd.Dir = dir
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Errorf("expected the model packages to build, got %v:\n%s", err, out)
+				}
+			}
+		})
+	}
+}
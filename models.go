@@ -0,0 +1,244 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen/templates"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// splitNotice marks the Go files split from generated files, so that the ones no prefix maps to anymore are deleted.
+const splitNotice = "// Split from %s by " + PluginName + "."
+
+// splitModels moves the models of the split types from the file generated by modelgen to a file per prefix
+// next to it, eg. User to users.models_gen.go.
+//
+// gqlgen runs the MutateConfig of modelgen before the ones of the plugins it's given, so the models file is
+// already generated. It's split rather than generated per prefix with a modelgen mutate hook, as the template
+// of modelgen isn't exported: splitting the file keeps the getters and the changes of other hooks.
+func (s *TypesSplitterPlugin) splitModels() error {
+	model := s.genCfg.Model
+	if (!s.cfg.SplitModels && !s.cfg.ModelPackages) || !model.IsDefined() {
+		return nil
+	}
+
+	src, err := os.ReadFile(model.Filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read models file: %w", err)
+	}
+
+	f, err := parseGoFile(model.Filename, src)
+	if err != nil {
+		return err
+	}
+
+	prefixes := s.modelPrefixes(s.genCfg.Schema)
+	if s.cfg.ModelPackages {
+		return s.splitModelPackages(f, prefixes)
+	}
+
+	goPrefixes := make(map[string]string)
+	for name, prefix := range prefixes {
+		goPrefixes[templates.ToGo(name)] = prefix
+	}
+
+	// the declarations of each prefix, in the order of the models file
+	decls := make(map[string][]string)
+	edits := &fileEdits{replaces: make(map[int]replace), removedImports: f.file.Imports}
+	for _, decl := range f.file.Decls {
+		prefix := goPrefixes[declaredType(decl)]
+		if prefix == "" {
+			continue
+		}
+
+		start, end := f.span(decl)
+		decls[prefix] = append(decls[prefix], string(f.src[start:end]))
+		edits.replaces[start] = replace{end: end}
+	}
+
+	base := filepath.Base(model.Filename)
+	notice := fmt.Sprintf(splitNotice, base)
+	written := make(map[string]bool)
+	for _, prefix := range sortedKeys(decls) {
+		p := filepath.Join(filepath.Dir(model.Filename), prefix+"."+base)
+
+		buf := bytes.NewBufferString(splitHeader(f, notice, f.file.Name.Name))
+		for _, decl := range decls[prefix] {
+			buf.WriteString("\n" + decl + "\n")
+		}
+
+		if err = writeGoFile(p, buf.Bytes(), nil, f.file.Imports); err != nil {
+			return err
+		}
+		written[p] = true
+	}
+
+	if len(decls) > 0 {
+		if err = edits.apply(f); err != nil {
+			return err
+		}
+	}
+
+	// files split by a previous run for prefixes without models anymore
+	stale, err := filepath.Glob(filepath.Join(filepath.Dir(model.Filename), "*."+base))
+	if err != nil {
+		return fmt.Errorf("failed to list model files: %w", err)
+	}
+	for _, p := range stale {
+		if written[p] {
+			continue
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read model file: %w", err)
+		}
+		if !bytes.Contains(content, []byte(notice)) {
+			continue
+		}
+		if err = os.Remove(p); err != nil {
+			return fmt.Errorf("failed to delete model file: %w", err)
+		}
+	}
+
+	// the model package changed since modelgen loaded it
+	if s.genCfg.Packages != nil {
+		s.genCfg.Packages.Evict(model.ImportPath())
+	}
+
+	return nil
+}
+
+// modelPrefixes returns the prefix of the types whose models are split, by schema type name: the object types
+// split by the rules, and the input types only used by the fields of a single prefix, including through other
+// input types.
+func (s *TypesSplitterPlugin) modelPrefixes(schema *ast.Schema) map[string]string {
+	prefixes := make(map[string]string)
+
+	// the prefixes of the fields using each input type as argument, an empty prefix for fields that weren't split
+	fieldUses := make(map[string]map[string]bool)
+	for _, def := range schema.Types {
+		if def.Kind != ast.Object {
+			continue
+		}
+
+		isRoot := def == schema.Query || def == schema.Mutation || def == schema.Subscription
+		if !isRoot && s.placements[def.Name] != "" {
+			prefixes[def.Name] = s.placements[def.Name]
+		}
+
+		for _, field := range def.Fields {
+			prefix := s.placements[def.Name]
+			if isRoot {
+				prefix = s.placements[def.Name+"."+field.Name]
+			}
+
+			for _, arg := range field.Arguments {
+				if isInput(schema, arg.Type) {
+					if fieldUses[arg.Type.Name()] == nil {
+						fieldUses[arg.Type.Name()] = make(map[string]bool)
+					}
+					fieldUses[arg.Type.Name()][prefix] = true
+				}
+			}
+		}
+	}
+
+	// input types take the prefix of the fields and input types using them, until no input type gets one anymore
+	inputs := make([]*ast.Definition, 0)
+	for _, def := range schema.Types {
+		if def.Kind == ast.InputObject {
+			inputs = append(inputs, def)
+		}
+	}
+	sort.Slice(inputs, func(i, j int) bool {
+		return inputs[i].Name < inputs[j].Name
+	})
+
+	for changed := true; changed; {
+		changed = false
+		for _, def := range inputs {
+			if prefixes[def.Name] != "" {
+				continue
+			}
+
+			uses := make(map[string]bool)
+			for prefix := range fieldUses[def.Name] {
+				uses[prefix] = true
+			}
+			for _, other := range inputs {
+				for _, field := range other.Fields {
+					if other != def && field.Type.Name() == def.Name {
+						uses[prefixes[other.Name]] = true
+					}
+				}
+			}
+
+			if len(uses) == 1 && !uses[""] {
+				prefixes[def.Name] = sortedKeys(uses)[0]
+				changed = true
+			}
+		}
+	}
+
+	return prefixes
+}
+
+// isInput returns whether the named type of the given type is an input type.
+func isInput(schema *ast.Schema, typ *ast.Type) bool {
+	def := schema.Types[typ.Name()]
+	return def != nil && def.Kind == ast.InputObject
+}
+
+// declaredType returns the name of the type a declaration of the models file belongs to: the declared type,
+// or the receiver of a method.
+func declaredType(decl goast.Decl) string {
+	switch decl := decl.(type) {
+	case *goast.GenDecl:
+		if decl.Tok == token.TYPE && len(decl.Specs) == 1 {
+			return decl.Specs[0].(*goast.TypeSpec).Name.Name
+		}
+	case *goast.FuncDecl:
+		if decl.Recv != nil && len(decl.Recv.List) > 0 {
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*goast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*goast.Ident); ok {
+				return ident.Name
+			}
+		}
+	}
+
+	return ""
+}
+
+// splitHeader returns the start of a file split from the given file: its header comment followed by the notice,
+// the package clause of the given package, and its imports.
+func splitHeader(f *goFile, notice, pkg string) string {
+	header := strings.TrimSpace(string(f.src[:f.fset.Position(f.file.Package).Offset]))
+	imports := string(f.src[f.fset.Position(f.file.Name.End()).Offset:importsEnd(f)])
+
+	return header + "\n" + notice + "\n\npackage " + pkg + imports + "\n"
+}
+
+// importsEnd returns the offset of the end of the imports of the file, or of its package clause without imports.
+func importsEnd(f *goFile) int {
+	end := f.file.Name.End()
+	for _, decl := range f.file.Decls {
+		if gen, ok := decl.(*goast.GenDecl); ok && gen.Tok == token.IMPORT {
+			end = gen.End()
+		}
+	}
+
+	return f.fset.Position(end).Offset
+}
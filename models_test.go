@@ -0,0 +1,187 @@
+package types_splitter_plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func Test_MutateConfig_SplitModels(t *testing.T) {
+	dir := t.TempDir()
+	modelDir := filepath.Join(dir, "graph", "model")
+
+	// the model package as generated by modelgen, before the plugin runs
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.20\n",
+		"graph/model/models_gen.go": `// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type Node interface {
+	IsNode()
+	GetID() string
+}
+
+type NameFilter struct {
+	Prefix *string ` + "`json:\"prefix,omitempty\"`" + `
+}
+
+type Post struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// A user
+type User struct {
+	ID   string ` + "`json:\"id\"`" + `
+	Role Role   ` + "`json:\"role\"`" + `
+}
+
+func (User) IsNode()            {}
+func (this User) GetID() string { return this.ID }
+
+type UserFilter struct {
+	Name *NameFilter ` + "`json:\"name,omitempty\"`" + `
+}
+
+type Role string
+
+const (
+	RoleAdmin Role = "ADMIN"
+)
+
+func (e Role) IsValid() bool {
+	return e == RoleAdmin
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+	*e = Role(str)
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(string(e)))
+}
+`,
+		// split by a previous run for a prefix without models anymore
+		"graph/model/editors.models_gen.go": "// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.\n" +
+			"// Split from models_gen.go by types_splitter.\n\npackage model\n",
+		// hand-written
+		"graph/model/custom.models_gen.go": "package model\n",
+	}
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sources := []*ast.Source{{Name: "schema.graphql", Input: `interface Node { id: ID! }
+enum Role { ADMIN }
+type Query {
+  getUser(id: ID!): User
+  findUsers(filter: UserFilter): [User!]!
+  getPost(id: ID!): Post
+}
+"""A user"""
+type User implements Node { id: ID! role: Role! }
+type Post { id: ID! }
+input UserFilter { name: NameFilter }
+input NameFilter { prefix: String }
+`}}
+	schema, err := gqlparser.LoadSchema(sources...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genCfg := &config.Config{
+		Sources: sources,
+		Schema:  schema,
+		Model:   config.PackageConfig{Filename: filepath.Join(modelDir, "models_gen.go"), Package: "model"},
+	}
+
+	cfg := getTestConfig(t, `
+types_splitter:
+  split_models: true
+  types:
+    - name: User
+      prefix: users
+  queries:
+    - prefix: users
+      matches:
+        - user
+`)
+
+	if err = (&TypesSplitterPlugin{cfg: cfg}).MutateConfig(genCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(modelDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	// the split object type, with its methods, and the input types only used by its root fields
+	users := read("users.models_gen.go")
+	for _, expected := range []string{
+		"// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.\n// Split from models_gen.go by types_splitter.\n\npackage model\n",
+		"// A user\ntype User struct",
+		"func (User) IsNode()",
+		"func (this User) GetID() string",
+		"type UserFilter struct",
+		"type NameFilter struct",
+	} {
+		if !strings.Contains(users, expected) {
+			t.Errorf("expected users.models_gen.go to contain %q, got:\n%s", expected, users)
+		}
+	}
+	if strings.Contains(users, "import") {
+		t.Errorf("expected users.models_gen.go without imports, got:\n%s", users)
+	}
+
+	// the models that weren't split stay in the models file
+	models := read("models_gen.go")
+	for _, expected := range []string{"type Node interface", "type Post struct", "type Role string", `"strconv"`} {
+		if !strings.Contains(models, expected) {
+			t.Errorf("expected models_gen.go to contain %q, got:\n%s", expected, models)
+		}
+	}
+	if strings.Contains(models, "User") || strings.Contains(models, "NameFilter") {
+		t.Errorf("expected the users models to be moved, got:\n%s", models)
+	}
+
+	if _, err = os.Stat(filepath.Join(modelDir, "editors.models_gen.go")); !os.IsNotExist(err) {
+		t.Errorf("expected the stale editors.models_gen.go to be deleted, got %v", err)
+	}
+	read("custom.models_gen.go")
+
+	if _, err := exec.LookPath("go"); err == nil {
+		cmd := exec.Command("go", "build", "./...")
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("expected the model package to build, got %v:\n%s", err, out)
+		}
+	}
+}
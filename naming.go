@@ -0,0 +1,295 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// typeKind is the kind of object types, root fields having the kind of their root type, see fieldKind.
+const typeKind = "type"
+
+// filenameKinds are the kinds that have a filename template.
+var filenameKinds = []string{"query", "mutation", "subscription", typeKind}
+
+const (
+	// LayoutFlat creates new sources next to their original source.
+	LayoutFlat = "flat"
+	// LayoutDomain creates new sources in a directory per prefix, next to their original source.
+	LayoutDomain = "domain"
+)
+
+// defaultFilenameTemplates are the filename templates of the kinds that don't have one in the config,
+// eg. queries.graphql => users.queries.graphql and types.graphql => users.graphql.
+var defaultFilenameTemplates = map[string]string{
+	"query":        "{prefix}.{original}{ext}",
+	"mutation":     "{prefix}.{original}{ext}",
+	"subscription": "{prefix}.{original}{ext}",
+	typeKind:       "{prefix}{ext}",
+}
+
+// domainFilenameTemplates are the default filename templates of the domain layout, eg.
+// queries.graphql => managers/users/managers.users.queries.graphql. The names keep the prefix so that
+// their base names, used by gqlgen to name resolver and exec files, stay unique.
+var domainFilenameTemplates = map[string]string{
+	"query":        "{prefix_dir}/{prefix}.{original}{ext}",
+	"mutation":     "{prefix_dir}/{prefix}.{original}{ext}",
+	"subscription": "{prefix_dir}/{prefix}.{original}{ext}",
+	typeKind:       "{prefix_dir}/{prefix}{ext}",
+}
+
+// filenamePlaceholders are the placeholders of filename templates.
+var filenamePlaceholders = map[string]bool{"prefix": true, "prefix_dir": true, "kind": true, "original": true, "ext": true}
+
+var filenamePlaceholderRegex = regexp.MustCompile(`\{[^{}]*\}`)
+
+// splitSourceName returns the name of the source the root fields or types of the given kind of the given
+// source are split into for the given prefix. The name is relative to the directory of the original source.
+func (c *SplitterConfig) splitSourceName(sourceName, kind, prefix string) string {
+	dir, original, ext := c.originalParts(sourceName)
+
+	name := filenamePlaceholderRegex.ReplaceAllStringFunc(c.filenameTemplate(kind), func(placeholder string) string {
+		switch placeholder {
+		case "{prefix}":
+			return prefix
+		case "{prefix_dir}":
+			return prefixDir(prefix)
+		case "{kind}":
+			return kind
+		case "{original}":
+			return original
+		case "{ext}":
+			return ext
+		default:
+			return placeholder
+		}
+	})
+
+	return filepath.Join(filepath.FromSlash(dir), filepath.FromSlash(name))
+}
+
+// filenameTemplate returns the filename template of the given kind.
+func (c *SplitterConfig) filenameTemplate(kind string) string {
+	var tpl string
+
+	switch kind {
+	case "query":
+		tpl = c.FilenameTemplates.Query
+	case "mutation":
+		tpl = c.FilenameTemplates.Mutation
+	case "subscription":
+		tpl = c.FilenameTemplates.Subscription
+	case typeKind:
+		tpl = c.FilenameTemplates.Type
+	}
+
+	if tpl != "" {
+		return tpl
+	}
+	if c.Layout == LayoutDomain {
+		return domainFilenameTemplates[kind]
+	}
+	return defaultFilenameTemplates[kind]
+}
+
+// prefixDir returns the directory of a prefix in the domain layout, eg. managers.users => managers/users.
+func prefixDir(prefix string) string {
+	return strings.ReplaceAll(prefix, ".", "/")
+}
+
+// originalParts returns the directory, the name without extension and the extension of the original source
+// of the given source. Sources written by the plugin are recognised with the filename templates, so that they
+// aren't split again into a prefixed name or directory, eg. users.queries.graphql => ., queries, .graphql.
+func (c *SplitterConfig) originalParts(sourceName string) (dir, original, ext string) {
+	name := filepath.ToSlash(sourceName)
+	base := path.Base(name)
+	dir, ext = path.Dir(name), path.Ext(base)
+	original = strings.TrimSuffix(base, ext)
+
+	prefixes := c.prefixes()
+	if len(prefixes) == 0 {
+		return dir, original, ext
+	}
+
+	for _, kind := range filenameKinds {
+		var re *regexp.Regexp
+		var match []string
+		for _, prefix := range prefixes {
+			re = filenameRegex(c.filenameTemplate(kind), kind, prefix)
+			if match = re.FindStringSubmatch(name); match != nil {
+				break
+			}
+		}
+		if match == nil {
+			continue
+		}
+
+		for i, group := range re.SubexpNames() {
+			switch {
+			case group == "dir":
+				if dir = match[i]; dir == "" {
+					dir = "."
+				}
+			case group == "original" && match[i] != "":
+				original = match[i]
+			case group == "ext" && match[i] != "":
+				ext = match[i]
+			}
+		}
+
+		return dir, original, ext
+	}
+
+	return dir, original, ext
+}
+
+// filenameRegex returns the regex matching the source names rendered by the filename template for the given kind
+// and prefix, capturing the directory of the original source.
+func filenameRegex(tpl, kind, prefix string) *regexp.Regexp {
+	expr := &strings.Builder{}
+	expr.WriteString("^(?:(?P<dir>.*)/)?")
+
+	last := 0
+	for _, loc := range filenamePlaceholderRegex.FindAllStringIndex(tpl, -1) {
+		expr.WriteString(regexp.QuoteMeta(tpl[last:loc[0]]))
+		last = loc[1]
+
+		// placeholders that appear more than once are only captured once
+		switch placeholder := tpl[loc[0]:loc[1]]; {
+		case placeholder == "{prefix}":
+			expr.WriteString(regexp.QuoteMeta(prefix))
+		case placeholder == "{prefix_dir}":
+			expr.WriteString(regexp.QuoteMeta(prefixDir(prefix)))
+		case placeholder == "{kind}":
+			expr.WriteString(regexp.QuoteMeta(kind))
+		case placeholder == "{original}" && !strings.Contains(expr.String(), "?P<original>"):
+			expr.WriteString(`(?P<original>[^/]+?)`)
+		case placeholder == "{ext}" && !strings.Contains(expr.String(), "?P<ext>"):
+			expr.WriteString(`(?P<ext>\.[^./]+)`)
+		case placeholder == "{original}":
+			expr.WriteString(`[^/]+?`)
+		case placeholder == "{ext}":
+			expr.WriteString(`\.[^./]+`)
+		default:
+			expr.WriteString(regexp.QuoteMeta(placeholder))
+		}
+	}
+	expr.WriteString(regexp.QuoteMeta(tpl[last:]))
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String())
+}
+
+// validateFilenameTemplate returns an error if the filename template can't produce a valid source name
+// for every prefix.
+func validateFilenameTemplate(tpl string) error {
+	for _, placeholder := range filenamePlaceholderRegex.FindAllString(tpl, -1) {
+		if !filenamePlaceholders[placeholder[1:len(placeholder)-1]] {
+			return fmt.Errorf("filename template %q has an unknown placeholder %s, use {prefix}, {prefix_dir}, {kind}, {original} or {ext}", tpl, placeholder)
+		}
+	}
+
+	if !strings.Contains(tpl, "{prefix}") && !strings.Contains(tpl, "{prefix_dir}") {
+		return fmt.Errorf("filename template %q must contain {prefix} or {prefix_dir}", tpl)
+	}
+
+	if strings.HasPrefix(tpl, "/") || strings.Contains(tpl, `\`) {
+		return fmt.Errorf("filename template %q must be a relative path using / separators", tpl)
+	}
+
+	for _, segment := range strings.Split(tpl, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("filename template %q must not have empty, . or .. path segments", tpl)
+		}
+	}
+
+	return nil
+}
+
+// checkFilenameTemplates validates the filename templates of the config. Templates whose base names
+// are shared by the sources of different prefixes are rejected if the config is strict.
+func (c *SplitterConfig) checkFilenameTemplates() error {
+	for _, kind := range filenameKinds {
+		if err := validateFilenameTemplate(c.filenameTemplate(kind)); err != nil {
+			return fmt.Errorf("invalid config: filename_template.%s: %w", kind, err)
+		}
+	}
+
+	if warnings := c.filenameTemplateWarnings(); c.Strict && len(warnings) > 0 {
+		return fmt.Errorf("invalid config:\n  %s", strings.Join(warnings, "\n  "))
+	}
+
+	return nil
+}
+
+// filenameTemplateWarnings returns a warning for each filename template without {prefix} in its base name.
+// The sources of different prefixes then share a base name, which checkBaseNames rejects with the
+// follow-schema resolver layout.
+func (c *SplitterConfig) filenameTemplateWarnings() (warnings []string) {
+	for _, kind := range filenameKinds {
+		tpl := c.filenameTemplate(kind)
+		if !strings.Contains(path.Base(tpl), "{prefix}") {
+			warnings = append(warnings, fmt.Sprintf("filename_template.%s: %q has no {prefix} in its base name, the sources of different prefixes would share it, which the follow-schema resolver layout rejects", kind, tpl))
+		}
+	}
+
+	return warnings
+}
+
+// prefixes returns all the prefixes of the config, longest first.
+func (c *SplitterConfig) prefixes() []string {
+	var prefixes []string
+
+	for _, q := range c.QueryConfig {
+		prefixes = append(prefixes, q.ResolverPrefix)
+	}
+	for _, t := range c.TypeConfig {
+		prefixes = append(prefixes, t.ResolverPrefix)
+	}
+
+	sort.SliceStable(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+
+	return prefixes
+}
+
+// checkBaseNames returns an error if a new source has the same base name, without extension, as another source.
+// gqlgen names resolver and exec files after the base names of the sources with the follow-schema layout, so
+// sources in different directories must still have different base names.
+func checkBaseNames(sources []*ast.Source, isNew func(src *ast.Source) bool) error {
+	byBase := make(map[string][]*ast.Source)
+	for _, src := range sources {
+		base := filepath.Base(src.Name)
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+		byBase[base] = append(byBase[base], src)
+	}
+
+	var conflicts []string
+	for _, base := range sortedKeys(byBase) {
+		if len(byBase[base]) < 2 {
+			continue
+		}
+
+		var names []string
+		hasNew := false
+		for _, src := range byBase[base] {
+			names = append(names, src.Name)
+			hasNew = hasNew || isNew(src)
+		}
+		if hasNew {
+			conflicts = append(conflicts, strings.Join(names, ", "))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("sources must have different base names, as gqlgen names resolver files after them: %s", strings.Join(conflicts, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package types_splitter_plugin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_splitSourceName(t *testing.T) {
+	tests := []struct {
+		name       string
+		templates  FilenameTemplatesConfig
+		sourceName string
+		kind       string
+		prefix     string
+		expected   string
+	}{
+		{"default query", FilenameTemplatesConfig{}, "schema/queries.graphql", "query", "users", "schema/users.queries.graphql"},
+		{"default type", FilenameTemplatesConfig{}, "schema/types.graphql", typeKind, "users", "schema/users.graphql"},
+		{"split source", FilenameTemplatesConfig{}, "schema/posts.queries.graphql", "query", "users", "schema/users.queries.graphql"},
+		{"dotted prefix", FilenameTemplatesConfig{}, "schema/managers.users.queries.graphql", "query", "users", "schema/users.queries.graphql"},
+		{"source named after a prefix", FilenameTemplatesConfig{}, "schema/users.graphql", "query", "posts", "schema/posts.users.graphql"},
+		{"directory", FilenameTemplatesConfig{Query: "{prefix}/{original}{ext}"}, "schema/queries.graphql", "query", "users", "schema/users/queries.graphql"},
+		{"directory split source", FilenameTemplatesConfig{Query: "{prefix}/{original}{ext}"}, "schema/posts/queries.graphql", "query", "users", "schema/users/queries.graphql"},
+		{"kind", FilenameTemplatesConfig{Mutation: "{prefix}_{kind}.graphqls"}, "schema/schema.graphql", "mutation", "users", "schema/users_mutation.graphqls"},
+		{"kind split source", FilenameTemplatesConfig{Mutation: "{prefix}_{kind}.graphqls"}, "schema/posts_mutation.graphqls", "mutation", "users", "schema/users_mutation.graphqls"},
+		{"types directory", FilenameTemplatesConfig{Type: "{prefix}/types{ext}"}, "schema/posts/types.graphql", typeKind, "users", "schema/users/types.graphql"},
+		{"prefix directory", FilenameTemplatesConfig{Query: "{prefix_dir}/{original}{ext}"}, "schema/queries.graphql", "query", "managers.users", "schema/managers/users/queries.graphql"},
+		{"prefix directory split source", FilenameTemplatesConfig{Query: "{prefix_dir}/{original}{ext}"}, "schema/managers/users/queries.graphql", "query", "posts", "schema/posts/queries.graphql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &SplitterConfig{
+				QueryConfig:       QuerySplitConfigs{{ResolverPrefix: "users"}, {ResolverPrefix: "posts"}, {ResolverPrefix: "managers.users"}},
+				FilenameTemplates: tt.templates,
+			}
+
+			if actual := cfg.splitSourceName(tt.sourceName, tt.kind, tt.prefix); actual != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+
+			// split sources are recognised, so that splitting them again with the same prefix keeps them in place
+			if actual := cfg.splitSourceName(tt.expected, tt.kind, tt.prefix); actual != tt.expected {
+				t.Errorf("expected %s to be kept in place, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_validateFilenameTemplate(t *testing.T) {
+	tests := []struct {
+		tpl string
+		err string
+	}{
+		{"{prefix}.{original}{ext}", ""},
+		{"{prefix}/{kind}.graphqls", ""},
+		{"{original}{ext}", "must contain {prefix} or {prefix_dir}"},
+		{"{prefix_dir}/types{ext}", ""},
+		{"{prefix}.{name}{ext}", "unknown placeholder {name}"},
+		{"/{prefix}{ext}", "must be a relative path"},
+		{`{prefix}\{original}{ext}`, "must be a relative path"},
+		{"../{prefix}{ext}", "must not have empty, . or .. path segments"},
+		{"{prefix}//{original}{ext}", "must not have empty, . or .. path segments"},
+	}
+
+	for _, tt := range tests {
+		err := validateFilenameTemplate(tt.tpl)
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: unexpected error %s", tt.tpl, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: expected error %q, got %v", tt.tpl, tt.err, err)
+		}
+	}
+}
+
+func Test_MutateConfig_FilenameTemplates(t *testing.T) {
+	cfg := getTestConfig(t, `
+types_splitter:
+  filename_template:
+    query: "{prefix}/{kind}{ext}"
+    mutation: "{prefix}/{kind}{ext}"
+  queries:
+    - prefix: posts
+      matches:
+        - post
+`)
+
+	genCfg := getTestGenConfig(t)
+	splitter := &TypesSplitterPlugin{cfg: cfg}
+	if err := splitter.MutateConfig(genCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, src := range genCfg.Sources {
+		names = append(names, src.Name)
+	}
+	if strings.Join(names, ",") != "directives.graphql,enums.graphql,interfaces.graphql,mutations.graphql,posts.graphql,posts/mutation.graphql,posts/query.graphql,queries.graphql,users.graphql" {
+		t.Errorf("unexpected sources %s", names)
+	}
+
+	// the sources of every prefix are named query.graphql and mutation.graphql
+	expected := []string{
+		`filename_template.query: "{prefix}/{kind}{ext}" has no {prefix} in its base name, the sources of different prefixes would share it, which the follow-schema resolver layout rejects`,
+		`filename_template.mutation: "{prefix}/{kind}{ext}" has no {prefix} in its base name, the sources of different prefixes would share it, which the follow-schema resolver layout rejects`,
+	}
+	if !reflect.DeepEqual(splitter.Warnings(), expected) {
+		t.Errorf("expected warnings %q, got %q", expected, splitter.Warnings())
+	}
+
+	// and rejected if the config is strict
+	_, err := readConfig(strings.NewReader(`
+types_splitter:
+  strict: true
+  filename_template:
+    query: "{prefix}/{kind}{ext}"
+  queries:
+    - prefix: posts
+      matches:
+        - post
+`))
+	if err == nil || !strings.Contains(err.Error(), `filename_template.query: "{prefix}/{kind}{ext}" has no {prefix} in its base name`) {
+		t.Errorf("expected a filename template error, got %v", err)
+	}
+
+	// root fields and types can't share a source
+	cfg = getTestConfig(t, `
+types_splitter:
+  filename_template:
+    query: "{prefix}{ext}"
+  types:
+    - name: Post
+      prefix: blog
+  queries:
+    - prefix: blog
+      matches:
+        - post
+`)
+
+	err = (&TypesSplitterPlugin{cfg: cfg}).MutateConfig(getTestGenConfig(t))
+	if err == nil || !strings.Contains(err.Error(), "blog.graphql would contain both query and type definitions") {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
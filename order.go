@@ -0,0 +1,96 @@
+package types_splitter_plugin
+
+import (
+	"sort"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const (
+	// OrderOriginal orders the fields and types of new sources by original source and position.
+	OrderOriginal = "original"
+	// OrderAlphabetical orders the fields and types of new sources by name.
+	OrderAlphabetical = "alphabetical"
+	// OrderConfig orders the fields and types of new sources in the order of the rules and regexes
+	// that matched them, and by original source and position for the same match.
+	OrderConfig = "config"
+)
+
+// sortSource orders the fields and types of a new source according to the Order of the config.
+func (s *TypesSplitterPlugin) sortSource(src *Source) {
+	sort.SliceStable(src.Fields, func(i, j int) bool {
+		a, b := src.Fields[i], src.Fields[j]
+
+		switch s.cfg.Order {
+		case OrderAlphabetical:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case OrderConfig:
+			if ra, rb := s.fieldRank(src, a), s.fieldRank(src, b); ra != rb {
+				return ra.less(rb)
+			}
+		}
+
+		return lessOriginal(a.OriginalPosition, b.OriginalPosition)
+	})
+
+	sort.SliceStable(src.Types, func(i, j int) bool {
+		a, b := src.Types[i], src.Types[j]
+
+		switch s.cfg.Order {
+		case OrderAlphabetical:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case OrderConfig:
+			if ra, rb := s.typeRank(src, a), s.typeRank(src, b); ra != rb {
+				return ra.less(rb)
+			}
+		}
+
+		return lessOriginal(a.OriginalPosition, b.OriginalPosition)
+	})
+}
+
+// ruleRank is the position of the rule, and of the regex of the rule, that placed a field or a type.
+type ruleRank struct {
+	rule, match int
+}
+
+func (r ruleRank) less(other ruleRank) bool {
+	if r.rule != other.rule {
+		return r.rule < other.rule
+	}
+	return r.match < other.match
+}
+
+// fieldRank returns the rank of the rule that placed the root field in the source. Fields placed
+// by the lock file rather than by a rule come last.
+func (s *TypesSplitterPlugin) fieldRank(src *Source, field *FieldDefinition) ruleRank {
+	qi := s.cfg.QueryConfig.findRuleIndex(field.Name)
+	if qi < 0 || s.cfg.QueryConfig[qi].ResolverPrefix != src.Prefix {
+		return ruleRank{rule: len(s.cfg.QueryConfig)}
+	}
+
+	return ruleRank{rule: qi, match: s.cfg.QueryConfig[qi].matchingIndex(field.Name)}
+}
+
+// typeRank returns the rank of the rule that placed the type in the source. Types placed by the
+// lock file rather than by a rule come last.
+func (s *TypesSplitterPlugin) typeRank(src *Source, def *Definition) ruleRank {
+	ti := s.cfg.TypeConfig.findRuleIndex(def.Name)
+	if ti < 0 || s.cfg.TypeConfig[ti].ResolverPrefix != src.Prefix {
+		return ruleRank{rule: len(s.cfg.TypeConfig)}
+	}
+
+	return ruleRank{rule: ti}
+}
+
+// lessOriginal returns whether the first position comes before the second one, by source name and offset.
+func lessOriginal(a, b ast.Position) bool {
+	if a.Src.Name != b.Src.Name {
+		return a.Src.Name < b.Src.Name
+	}
+	return a.Start < b.Start
+}
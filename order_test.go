@@ -0,0 +1,69 @@
+package types_splitter_plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_MutateConfig_Order(t *testing.T) {
+	tests := []struct {
+		order   string
+		queries []string
+		types   []string
+	}{
+		{
+			order:   OrderOriginal,
+			queries: []string{"Query.getPost", "Query.getPostsByEditor"},
+			types:   []string{"Post", "PostConnection", "Editor"},
+		},
+		{
+			order:   OrderAlphabetical,
+			queries: []string{"Query.getPost", "Query.getPostsByEditor"},
+			types:   []string{"Editor", "Post", "PostConnection"},
+		},
+		{
+			order:   OrderConfig,
+			queries: []string{"Query.getPostsByEditor", "Query.getPost"},
+			types:   []string{"PostConnection", "Post", "Editor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			cfg := getTestConfig(t, `
+types_splitter:
+  order: `+tt.order+`
+  types:
+    - name: PostConnection
+      prefix: blog
+    - name: Post
+      prefix: blog
+    - name: Editor
+      prefix: blog
+  queries:
+    - prefix: blog
+      matches:
+        - editor
+        - post
+`)
+
+			splitter := &TypesSplitterPlugin{cfg: cfg}
+			if err := splitter.MutateConfig(getTestGenConfig(t)); err != nil {
+				t.Fatal(err)
+			}
+
+			// moves are sorted by source and line, so they follow the order of the generated sources
+			placed := map[string][]string{}
+			for _, move := range splitter.Manifest().Moves {
+				placed[move.Source] = append(placed[move.Source], move.Name)
+			}
+
+			if !reflect.DeepEqual(placed["blog.queries.graphql"], tt.queries) {
+				t.Errorf("expected queries %v, got %v", tt.queries, placed["blog.queries.graphql"])
+			}
+			if !reflect.DeepEqual(placed["blog.graphql"], tt.types) {
+				t.Errorf("expected types %v, got %v", tt.types, placed["blog.graphql"])
+			}
+		})
+	}
+}
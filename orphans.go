@@ -0,0 +1,174 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+)
+
+const (
+	// OrphanReport reports the orphan resolver files as warnings.
+	OrphanReport = "report"
+	// OrphanDelete deletes the orphan resolver files.
+	OrphanDelete = "delete"
+)
+
+// resolverNotice is the start of the notice gqlgen writes in the resolver files it generates. Files without it
+// are never considered orphans.
+const resolverNotice = "// This file will be automatically regenerated based on the schema"
+
+// GenerateCode implements plugin.CodeGenerator. It runs after gqlgen generated the resolvers, moves the implementations
+// of the resolvers that changed files, generates the packages of the resolvers of each prefix and the tests of the
+// resolvers of the new sources, then reports or deletes the resolver files that no source maps to anymore, eg. after
+// a rule changed or an original source was emptied.
+// The exec files split by SplitExec are deleted, to be split again once gqlgen generated the exec code.
+func (s *TypesSplitterPlugin) GenerateCode(data *codegen.Data) error {
+	s.data = data
+
+	if err := s.removeSplitExec(data); err != nil {
+		return err
+	}
+
+	if err := s.moveImplementations(data); err != nil {
+		return err
+	}
+
+	if err := s.generateResolverPackages(data); err != nil {
+		return err
+	}
+
+	if err := s.generateResolverTests(data); err != nil {
+		return err
+	}
+
+	orphans, err := orphanResolvers(data, s.cfg.KeepResolvers)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range orphans {
+		// the dry-run leaves the files untouched
+		if s.cfg.OrphanResolvers == OrphanDelete && !s.dryRun {
+			if err = os.Remove(file); err != nil {
+				return fmt.Errorf("failed to delete orphan resolver file: %w", err)
+			}
+			continue
+		}
+
+		s.warnf("resolver file %s isn't generated from any source anymore, delete it or add it to keep_resolvers", file)
+	}
+
+	return nil
+}
+
+// orphanResolvers returns the resolver files of the follow-schema layout that weren't generated from any source,
+// that is the files matching the resolver filename template and starting with the gqlgen notice, except the
+// resolver type file and the files matching the keep patterns.
+func orphanResolvers(data *codegen.Data, keep []string) ([]string, error) {
+	resolver := data.Config.Resolver
+	if !resolver.IsDefined() || resolver.Layout != config.LayoutFollowSchema {
+		return nil, nil
+	}
+
+	filenameTemplate := resolverFilenameTemplate(resolver)
+	generated := generatedResolverFiles(data)
+
+	files, err := filepath.Glob(filepath.Join(resolver.Dir(), strings.ReplaceAll(filenameTemplate, "{name}", "*")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resolver files: %w", err)
+	}
+
+	var orphans []string
+	for _, file := range files {
+		if generated[file] {
+			continue
+		}
+
+		rel, err := filepath.Rel(resolver.Dir(), file)
+		if err != nil {
+			return nil, err
+		}
+		if keepResolver(filepath.ToSlash(rel), keep) {
+			continue
+		}
+
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read resolver file: %w", err)
+		}
+		if !strings.Contains(string(b), resolverNotice) {
+			continue
+		}
+
+		orphans = append(orphans, file)
+	}
+
+	sort.Strings(orphans)
+
+	return orphans, nil
+}
+
+// resolverFilenameTemplate returns the filename template of the resolver files of the follow-schema layout.
+func resolverFilenameTemplate(resolver config.ResolverConfig) string {
+	if resolver.FilenameTemplate == "" {
+		return "{name}" + ResolversSuffix + ".go"
+	}
+	return resolver.FilenameTemplate
+}
+
+// generatedResolverFiles returns the resolver files generated by gqlgen with the follow-schema layout, named after
+// the sources of the objects and fields with resolvers the same way gqlgen does, and the resolver type file.
+func generatedResolverFiles(data *codegen.Data) map[string]bool {
+	resolver := data.Config.Resolver
+	filenameTemplate := resolverFilenameTemplate(resolver)
+
+	generated := map[string]bool{resolver.Filename: true}
+	for _, objects := range []codegen.Objects{data.Objects, data.Inputs} {
+		for _, o := range objects {
+			if o.HasResolvers() {
+				generated[resolverFileName(resolver.Dir(), o.Position.Src.Name, filenameTemplate)] = true
+			}
+			for _, f := range o.Fields {
+				if f.IsResolver {
+					generated[resolverFileName(resolver.Dir(), f.Position.Src.Name, filenameTemplate)] = true
+				}
+			}
+		}
+	}
+
+	return generated
+}
+
+// resolverFileName returns the name of the resolver file of the given source, as named by gqlgen.
+func resolverFileName(dir, sourceName, filenameTemplate string) string {
+	base := filepath.Base(sourceName)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+
+	return filepath.Join(dir, strings.ReplaceAll(filenameTemplate, "{name}", name))
+}
+
+// keepResolver returns whether the resolver file, relative to the resolver dir, matches one of the keep patterns.
+func keepResolver(file string, keep []string) bool {
+	for _, pattern := range keep {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkKeepResolvers checks that the keep_resolvers patterns are valid.
+func (c *SplitterConfig) checkKeepResolvers() error {
+	for _, pattern := range c.KeepResolvers {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("keep_resolvers %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
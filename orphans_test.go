@@ -0,0 +1,96 @@
+package types_splitter_plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func Test_GenerateCode_OrphanResolvers(t *testing.T) {
+	generated := "package graph\n\n" + resolverNotice + ", any resolver implementations\n"
+
+	files := map[string]string{
+		// generated from users.queries.graphql
+		"users.queries.resolvers.go": generated,
+		// generated from queries.graphql before it was emptied by the split
+		"queries.resolvers.go": generated,
+		// kept by the keep_resolvers patterns
+		"legacy.resolvers.go": generated,
+		// hand-written, without the gqlgen notice
+		"helpers.resolvers.go": "package graph\n",
+		"resolver.go":          "package graph\n",
+	}
+
+	for _, mode := range []string{OrphanReport, OrphanDelete} {
+		t.Run(mode, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg := getTestConfig(t, `
+types_splitter:
+  orphan_resolvers: `+mode+`
+  keep_resolvers:
+    - legacy.*
+  types:
+    - name: User
+      prefix: users
+`)
+
+			src := &ast.Source{Name: filepath.Join("graph", "users.queries.graphql")}
+			data := &codegen.Data{
+				Config: &config.Config{
+					Resolver: config.ResolverConfig{
+						Layout:           config.LayoutFollowSchema,
+						DirName:          dir,
+						Filename:         filepath.Join(dir, "resolver.go"),
+						FilenameTemplate: "{name}.resolvers.go",
+					},
+				},
+				Objects: codegen.Objects{{
+					Definition: &ast.Definition{Name: "Query", Position: &ast.Position{Src: src}},
+					Fields: []*codegen.Field{{
+						FieldDefinition: &ast.FieldDefinition{Name: "getUser", Position: &ast.Position{Src: src}},
+						IsResolver:      true,
+					}},
+				}},
+			}
+
+			splitter := &TypesSplitterPlugin{cfg: cfg}
+			if err := splitter.GenerateCode(data); err != nil {
+				t.Fatal(err)
+			}
+
+			orphan := filepath.Join(dir, "queries.resolvers.go")
+			_, err := os.Stat(orphan)
+			if deleted := os.IsNotExist(err); deleted != (mode == OrphanDelete) {
+				t.Errorf("expected %s to be deleted: %t, got %t", orphan, mode == OrphanDelete, deleted)
+			}
+
+			var warnings []string
+			if mode == OrphanReport {
+				warnings = append(warnings, "resolver file "+orphan+" isn't generated from any source anymore, delete it or add it to keep_resolvers")
+			}
+			if strings.Join(splitter.Warnings(), "\n") != strings.Join(warnings, "\n") {
+				t.Errorf("expected warnings %q, got %q", warnings, splitter.Warnings())
+			}
+
+			for name := range files {
+				if name == "queries.resolvers.go" {
+					continue
+				}
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("expected %s to be kept, got %v", name, err)
+				}
+			}
+		})
+	}
+}
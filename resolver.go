@@ -0,0 +1,136 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const (
+	// ModeSchema splits the schema sources, and the resolvers with them.
+	ModeSchema = "schema"
+	// ModeResolvers only splits the resolver files, the schema sources are left as they were loaded.
+	ModeResolvers = "resolvers"
+)
+
+// resolverSuffix returns the suffix of the resolver files of the config.
+func (c *SplitterConfig) resolverSuffix() string {
+	if c.ResolverSuffix == "" {
+		return ResolversSuffix
+	}
+	return c.ResolverSuffix
+}
+
+// configureResolver composes the split with the resolver config of gqlgen. Resolvers follow the split sources
+// with the follow-schema layout only, whose filename template defaults to {name} followed by the resolver suffix.
+// Settings of the user are kept, unless resolver_layout forces the layout: those preventing resolvers from being
+// split are reported as warnings, or as errors in strict mode.
+func (s *TypesSplitterPlugin) configureResolver() error {
+	resolver := &s.genCfg.Resolver
+	if !resolver.IsDefined() {
+		return nil
+	}
+
+	// the layout forced by older versions of the plugin, kept by the migration of their configs
+	if config.ResolverLayout(s.cfg.ResolverLayout) == config.LayoutFollowSchema && resolver.Layout != config.LayoutFollowSchema {
+		resolver.Layout = config.LayoutFollowSchema
+		if resolver.DirName == "" {
+			resolver.DirName = filepath.Dir(resolver.Filename)
+		}
+	}
+
+	if s.cfg.ResolverPackages {
+		if resolver.Layout != config.LayoutFollowSchema {
+			return fmt.Errorf("resolver_packages requires the resolver layout follow-schema, got %s", resolver.Layout)
+		}
+
+		// the packages of the prefixes import the models, and are imported by the resolver package
+		if s.genCfg.Model.IsDefined() && filepath.Dir(s.genCfg.Model.Filename) == resolver.Dir() {
+			return fmt.Errorf("resolver_packages requires the models in another package than the resolvers, as the packages of the prefixes import them")
+		}
+	}
+
+	switch resolver.Layout {
+	case config.LayoutFollowSchema:
+		if resolver.FilenameTemplate == "" {
+			resolver.FilenameTemplate = "{name}" + s.cfg.resolverSuffix() + ".go"
+			return nil
+		}
+
+		if !strings.Contains(resolver.FilenameTemplate, "{name}") {
+			return fmt.Errorf("resolver filename_template %q must contain {name}, or the resolvers of every source are generated in the same file", resolver.FilenameTemplate)
+		}
+
+		if s.cfg.ResolverSuffix != "" {
+			s.warnf("resolver_suffix %q is ignored, the resolver filename_template %q is used", s.cfg.ResolverSuffix, resolver.FilenameTemplate)
+		}
+	default:
+		msg := fmt.Sprintf("resolver layout %s generates every resolver in %s, only the schema is split: use layout follow-schema with a dir to split the resolvers", resolver.Layout, resolver.Filename)
+		if s.cfg.Strict {
+			return fmt.Errorf("%s", msg)
+		}
+		s.warnf("%s", msg)
+	}
+
+	return nil
+}
+
+// followsSchema returns whether gqlgen generates files after the sources of the schema, resolvers or exec code
+// with the follow-schema layout, in which case the base names of the sources must be unique.
+func (s *TypesSplitterPlugin) followsSchema() bool {
+	return (s.genCfg.Resolver.IsDefined() && s.genCfg.Resolver.Layout == config.LayoutFollowSchema) ||
+		s.genCfg.Exec.Layout == config.ExecLayoutFollowSchema
+}
+
+// sourcesSnapshot is the state of the sources of a config as they were loaded, and of the positions of the
+// definitions and fields of its schema.
+type sourcesSnapshot struct {
+	sources   []*ast.Source
+	inputs    map[*ast.Source]string
+	positions map[*ast.Position]ast.Position
+}
+
+// takeSourcesSnapshot returns the state of the sources of the given config.
+func takeSourcesSnapshot(genCfg *config.Config) *sourcesSnapshot {
+	snapshot := &sourcesSnapshot{
+		sources:   append([]*ast.Source(nil), genCfg.Sources...),
+		inputs:    make(map[*ast.Source]string, len(genCfg.Sources)),
+		positions: make(map[*ast.Position]ast.Position),
+	}
+
+	for _, src := range genCfg.Sources {
+		snapshot.inputs[src] = src.Input
+	}
+
+	for _, def := range genCfg.Schema.Types {
+		if def.Position != nil {
+			snapshot.positions[def.Position] = *def.Position
+		}
+		for _, field := range def.Fields {
+			if field.Position != nil {
+				snapshot.positions[field.Position] = *field.Position
+			}
+		}
+	}
+
+	return snapshot
+}
+
+// restore restores the sources of the given config, so that the split only applies to the resolvers: moved
+// fields and types keep pointing to their split source, after which gqlgen names their resolver file, while
+// the definitions and fields left in their source get their original position back.
+func (snapshot *sourcesSnapshot) restore(genCfg *config.Config) {
+	genCfg.Sources = snapshot.sources
+	for src, input := range snapshot.inputs {
+		src.Input = input
+	}
+
+	for pos, original := range snapshot.positions {
+		if pos.Src == original.Src {
+			*pos = original
+		}
+	}
+}
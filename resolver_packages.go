@@ -0,0 +1,462 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/templates"
+)
+
+// domainsFilename is the name of the file of the Domains struct, generated in the resolver package.
+const domainsFilename = "domains.go"
+
+// invalidPackageChars matches the characters of a prefix that can't be used in a package name.
+var invalidPackageChars = regexp.MustCompile(`[^a-z0-9_]`)
+
+// resolverDomain is a prefix whose resolvers are generated in their own package, under the resolver dir.
+type resolverDomain struct {
+	Prefix     string
+	Field      string
+	Package    string
+	ImportPath string
+	Objects    []*codegen.Object
+	Resolvers  []*domainResolver
+
+	// RemainingSource is the code of the previous resolvers file that isn't generated anymore
+	RemainingSource string
+
+	dir     string
+	imports []*goast.ImportSpec
+}
+
+// domainResolver is a resolver generated in the package of its domain.
+type domainResolver struct {
+	Field          *codegen.Field
+	Implementation string
+}
+
+// Imports reserves the imports of the previous resolvers file of the domain, used by the implementations it keeps.
+func (d *resolverDomain) Imports() string {
+	for _, spec := range d.imports {
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		if spec.Name != nil {
+			_, _ = templates.CurrentImports.Reserve(importPath, spec.Name.Name)
+		} else {
+			_, _ = templates.CurrentImports.Reserve(importPath)
+		}
+	}
+	return ""
+}
+
+// domainResolversTemplate is the template of the resolvers file of a domain.
+const domainResolversTemplate = `{{ reserveImport "context" }}
+{{ reserveImport "fmt" }}
+{{ .Imports }}
+
+{{ range $object := .Objects -}}
+// {{ ucFirst $object.Name }} returns the resolvers of the {{ $object.Name }} fields of the domain.
+func (r *Resolver) {{ ucFirst $object.Name }}() *{{ ucFirst $object.Name }}Resolver { return &{{ ucFirst $object.Name }}Resolver{r} }
+
+// {{ ucFirst $object.Name }}Resolver resolves the {{ $object.Name }} fields of the domain.
+type {{ ucFirst $object.Name }}Resolver struct{ *Resolver }
+
+{{ end -}}
+
+{{ range $resolver := .Resolvers -}}
+// {{ $resolver.Field.GoFieldName }} is the resolver for the {{ $resolver.Field.Name }} field.
+func (r *{{ ucFirst $resolver.Field.Object.Name }}Resolver) {{ $resolver.Field.GoFieldName }}{{ $resolver.Field.ShortResolverDeclaration }} {
+	{{ $resolver.Implementation }}
+}
+
+{{ end -}}
+
+{{ if .RemainingSource }}
+// !!! WARNING !!!
+// The code below isn't generated anymore: helpers, or resolvers of fields that left the domain. It has been kept
+// so that it isn't lost, move the helpers to another file and delete the resolvers you don't need anymore.
+
+{{ .RemainingSource }}
+{{ end }}
+`
+
+// domainResolverTemplate is the template of the resolver type of a domain, generated once.
+const domainResolverTemplate = `type Resolver struct{}
+`
+
+// domainsTemplate is the template of the Domains struct of the resolver package.
+const domainsTemplate = `// Domains are the resolvers of the split prefixes, each generated in its own package. It's embedded in
+// {{ .Type }}, whose resolvers delegate to the packages of their prefix.
+type Domains struct {
+{{- range $domain := .Domains }}
+	{{ $domain.Field }} *{{ lookupImport $domain.ImportPath }}.Resolver
+{{- end }}
+}
+`
+
+// generateResolverPackages generates the resolvers of each prefix in its own package under the resolver dir, and
+// the Domains struct composing them in the resolver package, whose resolvers delegate to them.
+func (s *TypesSplitterPlugin) generateResolverPackages(data *codegen.Data) error {
+	if !s.cfg.ResolverPackages || s.dryRun {
+		return nil
+	}
+
+	domains := s.resolverDomains(data)
+	implemented, err := implementedResolvers(data, domains)
+	if err != nil {
+		return err
+	}
+
+	for _, domain := range domains {
+		if err = s.generateDomain(data, domain, implemented); err != nil {
+			return err
+		}
+	}
+
+	if len(domains) == 0 {
+		return nil
+	}
+
+	resolver := data.Config.Resolver
+	err = templates.Render(templates.Options{
+		PackageName: resolver.Package,
+		PackageDoc:  "// Code generated by " + PluginName + ", DO NOT EDIT.\n",
+		Filename:    filepath.Join(resolver.Dir(), domainsFilename),
+		Data: struct {
+			Type    string
+			Domains []*resolverDomain
+		}{resolver.Type, domains},
+		Packages: data.Config.Packages,
+		Template: domainsTemplate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate %s: %w", domainsFilename, err)
+	}
+
+	return s.delegateResolvers(data, domains)
+}
+
+// resolverDomains returns the domains of the resolvers of the schema, sorted by prefix. The domain of a root field
+// is its prefix, and the one of the field of a type is the prefix of the type.
+func (s *TypesSplitterPlugin) resolverDomains(data *codegen.Data) []*resolverDomain {
+	resolver := data.Config.Resolver
+	domains := make(map[string]*resolverDomain)
+
+	for _, objects := range []codegen.Objects{data.Objects, data.Inputs} {
+		for _, o := range objects {
+			for _, f := range o.Fields {
+				if !f.IsResolver {
+					continue
+				}
+
+				prefix := s.resolverPrefix(f)
+				if prefix == "" {
+					continue
+				}
+
+				domain := domains[prefix]
+				if domain == nil {
+					domain = &resolverDomain{
+						Prefix:     prefix,
+						Field:      templates.ToGo(strings.ReplaceAll(prefix, ".", "_")),
+						Package:    packageName(prefix),
+						ImportPath: resolver.ImportPath() + "/" + prefixDir(prefix),
+						dir:        filepath.Join(resolver.Dir(), filepath.FromSlash(prefixDir(prefix))),
+					}
+					domains[prefix] = domain
+				}
+
+				if len(domain.Objects) == 0 || domain.Objects[len(domain.Objects)-1] != o {
+					domain.Objects = append(domain.Objects, o)
+				}
+				domain.Resolvers = append(domain.Resolvers, &domainResolver{Field: f})
+			}
+		}
+	}
+
+	return mapToList(domains)
+}
+
+// resolverPrefix returns the prefix of the resolver of the given field.
+func (s *TypesSplitterPlugin) resolverPrefix(f *codegen.Field) string {
+	if f.Object.Root {
+		return s.placements[f.Object.Name+"."+f.Name]
+	}
+	return s.placements[f.Object.Name]
+}
+
+// packageName returns the name of the package of a prefix, the last part of its directory.
+func packageName(prefix string) string {
+	name := invalidPackageChars.ReplaceAllString(strings.ToLower(filepath.Base(prefixDir(prefix))), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// implementedResolvers returns the resolvers of the domains implemented in the resolver package, other than the stubs
+// of gqlgen and the delegations to the domains.
+func implementedResolvers(data *codegen.Data, domains []*resolverDomain) (map[*domainResolver]bool, error) {
+	current, err := loadGoFiles(data.Config.Resolver.Dir())
+	if err != nil {
+		return nil, err
+	}
+
+	methods := make(map[string]*goFile)
+	for _, p := range sortedKeys(current) {
+		for key := range current[p].methods() {
+			methods[key] = current[p]
+		}
+	}
+
+	implemented := make(map[*domainResolver]bool)
+	for _, domain := range domains {
+		for _, r := range domain.Resolvers {
+			key := rootResolverKey(data, r.Field)
+			if f := methods[key]; f != nil {
+				body := f.source(f.methods()[key].Body)
+				implemented[r] = !isStub(body) && !isDelegation(body, delegationCall(domain, r.Field))
+			}
+		}
+	}
+
+	return implemented, nil
+}
+
+// rootResolverKey returns the key of the resolver of the field in the resolver package, eg. queryResolver.GetUser.
+func rootResolverKey(data *codegen.Data, f *codegen.Field) string {
+	return templates.LcFirst(f.Object.Name) + templates.UcFirst(data.Config.Resolver.Type) + "." + f.GoFieldName
+}
+
+// delegationCall returns the start of the call of a resolver of the resolver package to the package of its domain.
+func delegationCall(domain *resolverDomain, f *codegen.Field) string {
+	return fmt.Sprintf("return r.%s.%s().%s(", domain.Field, templates.UcFirst(f.Object.Name), f.GoFieldName)
+}
+
+// generateDomain generates the package of a domain, keeping the implementations of its previous resolvers file.
+// The resolvers implemented in the resolver package are left there, without stub in the domain unless it implements
+// them too. The resolver type is only generated when it doesn't exist, as it's where dependencies are added.
+func (s *TypesSplitterPlugin) generateDomain(data *codegen.Data, domain *resolverDomain, implemented map[*domainResolver]bool) error {
+	if err := os.MkdirAll(domain.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create the package of %s: %w", domain.Prefix, err)
+	}
+
+	filename := resolverFileName(domain.dir, domain.Package, resolverFilenameTemplate(data.Config.Resolver))
+
+	// the generated declarations of the previous file are replaced, the rest is kept
+	var previous *goFile
+	if src, err := os.ReadFile(filename); err == nil {
+		if previous, err = parseGoFile(filename, src); err != nil {
+			return err
+		}
+	}
+
+	generated := make(map[string]bool)
+	for _, o := range domain.Objects {
+		generated["Resolver."+templates.UcFirst(o.Name)] = true
+		generated[templates.UcFirst(o.Name)+"Resolver"] = true
+	}
+
+	var resolvers []*domainResolver
+	for _, r := range domain.Resolvers {
+		key := templates.UcFirst(r.Field.Object.Name) + "Resolver." + r.Field.GoFieldName
+		generated[key] = true
+
+		r.Implementation = fmt.Sprintf("panic(fmt.Errorf(\"not implemented: %v - %v\"))", r.Field.GoFieldName, r.Field.Name)
+		if previous != nil {
+			if method := previous.methods()[key]; method != nil {
+				r.Implementation = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(previous.source(method.Body), "{"), "}"))
+			}
+		}
+
+		if implemented[r] && strings.HasPrefix(r.Implementation, notImplemented) {
+			continue
+		}
+		resolvers = append(resolvers, r)
+	}
+	domain.Resolvers = resolvers
+
+	if previous != nil {
+		domain.imports = previous.file.Imports
+		domain.RemainingSource = remainingSource(previous, generated)
+	}
+
+	err := templates.Render(templates.Options{
+		PackageName: domain.Package,
+		FileNotice:  resolverNotice + ", any resolver implementations\n// will be copied through when generating and any unknown code will be moved to the end.",
+		Filename:    filename,
+		Data:        domain,
+		Packages:    data.Config.Packages,
+		Template:    domainResolversTemplate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate the resolvers of %s: %w", domain.Prefix, err)
+	}
+
+	resolverFile := filepath.Join(domain.dir, filepath.Base(data.Config.Resolver.Filename))
+	if _, err = os.Stat(resolverFile); err == nil {
+		return nil
+	}
+
+	err = templates.Render(templates.Options{
+		PackageName: domain.Package,
+		FileNotice: "// This file will not be regenerated automatically.\n//\n// It serves as dependency injection for the " +
+			domain.Prefix + " resolvers, add any dependencies you require here.",
+		Filename: resolverFile,
+		Packages: data.Config.Packages,
+		Template: domainResolverTemplate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate the resolver of %s: %w", domain.Prefix, err)
+	}
+
+	return nil
+}
+
+// remainingSource returns the declarations of the file that aren't generated, with their doc comments.
+func remainingSource(f *goFile, generated map[string]bool) string {
+	var decls []string
+	for _, decl := range f.file.Decls {
+		switch decl := decl.(type) {
+		case *goast.FuncDecl:
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*goast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*goast.Ident); ok && generated[ident.Name+"."+decl.Name.Name] {
+					continue
+				}
+			}
+		case *goast.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+			if spec, ok := decl.Specs[0].(*goast.TypeSpec); ok && len(decl.Specs) == 1 && generated[spec.Name.Name] {
+				continue
+			}
+		}
+
+		source := f.source(decl)
+		if decl, ok := decl.(*goast.GenDecl); ok && decl.Doc != nil {
+			start, _ := f.span(decl.Doc)
+			_, end := f.span(decl)
+			source = string(f.src[start:end])
+		}
+		decls = append(decls, source)
+	}
+
+	return strings.Join(decls, "\n\n")
+}
+
+// isDelegation returns whether the body of a resolver method is only the given call to the package of its domain.
+func isDelegation(body, call string) bool {
+	body = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(body, "{"), "}"))
+	return strings.HasPrefix(body, call) && strings.HasSuffix(body, ")") && !strings.Contains(body, "\n")
+}
+
+// delegateResolvers makes the stubs generated by gqlgen in the resolver package delegate to the packages of their
+// domain, and embeds Domains in the resolver type. Resolvers that are already implemented are left as they are, and the
+// delegations are regenerated when the arguments of their field changed.
+func (s *TypesSplitterPlugin) delegateResolvers(data *codegen.Data, domains []*resolverDomain) error {
+	resolver := data.Config.Resolver
+
+	current, err := loadGoFiles(resolver.Dir())
+	if err != nil {
+		return err
+	}
+
+	edits := make(map[string]*fileEdits)
+	editsOf := func(p string) *fileEdits {
+		if edits[p] == nil {
+			edits[p] = &fileEdits{replaces: make(map[int]replace)}
+		}
+		return edits[p]
+	}
+
+	methods := make(map[string]*goFile)
+	for _, p := range sortedKeys(current) {
+		for key := range current[p].methods() {
+			methods[key] = current[p]
+		}
+	}
+
+	for _, domain := range domains {
+		for _, r := range domain.Resolvers {
+			key := rootResolverKey(data, r.Field)
+			f := methods[key]
+			if f == nil {
+				continue
+			}
+
+			var args []string
+			method := f.methods()[key]
+			for _, param := range method.Type.Params.List {
+				for _, name := range param.Names {
+					args = append(args, name.Name)
+				}
+			}
+
+			// delegations generated by a previous run are regenerated, as the arguments may have changed since
+			call := delegationCall(domain, r.Field)
+			body := f.source(method.Body)
+			delegation := fmt.Sprintf("{\n\t%s%s)\n}", call, strings.Join(args, ", "))
+			if body == delegation || !isStub(body) && !isDelegation(body, call) {
+				continue
+			}
+
+			start, end := f.span(method.Body)
+			editsOf(f.path).replaces[start] = replace{end: end, text: delegation}
+			editsOf(f.path).removedImports = append(editsOf(f.path).removedImports, notImplementedImport)
+		}
+	}
+
+	// Domains is embedded once in the resolver type
+	if f := current[resolver.Filename]; f != nil {
+		if embedded, opening := embedsDomains(f, resolver.Type); !embedded && opening >= 0 {
+			editsOf(f.path).replaces[opening] = replace{end: opening, text: "\n\tDomains\n"}
+		}
+	}
+
+	for _, p := range sortedKeys(edits) {
+		if err = edits[p].apply(current[p]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// embedsDomains returns whether the struct of the given type embeds Domains, and the offset after its opening brace,
+// -1 when the type isn't a struct of the file.
+func embedsDomains(f *goFile, typeName string) (bool, int) {
+	for _, decl := range f.file.Decls {
+		decl, ok := decl.(*goast.GenDecl)
+		if !ok || decl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range decl.Specs {
+			spec := spec.(*goast.TypeSpec)
+			st, ok := spec.Type.(*goast.StructType)
+			if !ok || spec.Name.Name != typeName {
+				continue
+			}
+
+			for _, field := range st.Fields.List {
+				if ident, ok := field.Type.(*goast.Ident); ok && len(field.Names) == 0 && ident.Name == "Domains" {
+					return true, -1
+				}
+			}
+
+			return false, f.fset.Position(st.Fields.Opening).Offset + 1
+		}
+	}
+
+	return false, -1
+}
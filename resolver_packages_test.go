@@ -0,0 +1,176 @@
+package types_splitter_plugin
+
+import (
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func Test_GenerateCode_ResolverPackages(t *testing.T) {
+	dir := t.TempDir()
+	graphDir := filepath.Join(dir, "graph")
+
+	// the resolver package as generated by gqlgen, before the plugin runs
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.20\n",
+		"graph/resolver.go": `package graph
+
+type Resolver struct{}
+`,
+		"graph/users.queries.resolvers.go": `package graph
+
+` + resolverNotice + `, any resolver implementations
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetUser is the resolver for the getUser field.
+func (r *queryResolver) GetUser(ctx context.Context, id string) (string, error) {
+	panic(fmt.Errorf("not implemented: GetUser - getUser"))
+}
+
+// GetPost is the resolver for the getPost field.
+func (r *queryResolver) GetPost(ctx context.Context) (string, error) {
+	return "post", nil
+}
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() *queryResolver { return &queryResolver{r} }
+
+type queryResolver struct{ *Resolver }
+`,
+	}
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	genCfg := &config.Config{
+		Resolver: config.ResolverConfig{
+			Layout:           config.LayoutFollowSchema,
+			DirName:          graphDir,
+			Filename:         filepath.Join(graphDir, "resolver.go"),
+			FilenameTemplate: "{name}.resolvers.go",
+			Package:          "graph",
+			Type:             "Resolver",
+		},
+	}
+	// the packages cache of gqlgen is internal, a zero one is created for the templates to render
+	reflect.ValueOf(&genCfg.Packages).Elem().Set(reflect.New(reflect.TypeOf(genCfg.Packages).Elem()))
+
+	query := &codegen.Object{
+		Definition: &ast.Definition{Name: "Query", Position: &ast.Position{Src: &ast.Source{Name: "users.queries.graphql"}}},
+		Root:       true,
+	}
+	query.Fields = []*codegen.Field{{
+		FieldDefinition: &ast.FieldDefinition{Name: "getUser", Position: query.Position},
+		Object:          query,
+		GoFieldName:     "GetUser",
+		IsResolver:      true,
+		TypeReference:   &config.TypeReference{GO: types.Typ[types.String]},
+		Args: []*codegen.FieldArgument{{
+			ArgumentDefinition: &ast.ArgumentDefinition{Name: "id"},
+			VarName:            "id",
+			TypeReference:      &config.TypeReference{GO: types.Typ[types.String]},
+		}},
+	}, {
+		// implemented in the resolver package before the split
+		FieldDefinition: &ast.FieldDefinition{Name: "getPost", Position: query.Position},
+		Object:          query,
+		GoFieldName:     "GetPost",
+		IsResolver:      true,
+		TypeReference:   &config.TypeReference{GO: types.Typ[types.String]},
+	}}
+
+	splitter := &TypesSplitterPlugin{
+		cfg:        getTestConfig(t, "types_splitter:\n  resolver_packages: true\n  queries:\n    - prefix: users\n      matches: [user]\n"),
+		placements: map[string]string{"Query.getUser": "users", "Query.getPost": "users"},
+	}
+
+	// the second run must leave the generated files unchanged
+	var previous map[string]string
+	for run := 1; run <= 2; run++ {
+		if err := splitter.GenerateCode(&codegen.Data{Config: genCfg, Objects: codegen.Objects{query}}); err != nil {
+			t.Fatal(err)
+		}
+
+		generated := make(map[string]string)
+		for _, name := range []string{"graph/domains.go", "graph/resolver.go", "graph/users.queries.resolvers.go", "graph/users/users.resolvers.go", "graph/users/resolver.go"} {
+			b, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			generated[name] = string(b)
+		}
+
+		expected := map[string]string{
+			"graph/domains.go":                 "\tUsers *users.Resolver\n",
+			"graph/resolver.go":                "type Resolver struct {\n\tDomains\n}",
+			"graph/users.queries.resolvers.go": "\treturn r.Users.Query().GetUser(ctx, id)\n",
+			"graph/users/users.resolvers.go":   "func (r *QueryResolver) GetUser(ctx context.Context, id string) (string, error) {\n\tpanic(",
+			"graph/users/resolver.go":          "type Resolver struct{}",
+		}
+		for name, content := range expected {
+			if !strings.Contains(generated[name], content) {
+				t.Errorf("run %d: expected %s to contain:\n%s\ngot:\n%s", run, name, content, generated[name])
+			}
+		}
+
+		// the implementation is kept in the resolver package, without stub in the package of the prefix
+		if !strings.Contains(generated["graph/users.queries.resolvers.go"], "\treturn \"post\", nil\n") || strings.Contains(generated["graph/users/users.resolvers.go"], "GetPost") {
+			t.Errorf("run %d: expected GetPost to stay in the resolver package only, got:\n%s", run, generated["graph/users/users.resolvers.go"])
+		}
+
+		if run == 2 && !reflect.DeepEqual(generated, previous) {
+			t.Errorf("expected the second run to leave the files unchanged, got %v", generated)
+		}
+		previous = generated
+	}
+
+	// gqlgen keeps the delegation when the field gains an argument, with the new signature
+	resolvers := filepath.Join(graphDir, "users.queries.resolvers.go")
+	b, err := os.ReadFile(resolvers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = []byte(strings.Replace(string(b), "GetUser(ctx context.Context, id string)", "GetUser(ctx context.Context, id string, name *string)", 1))
+	if err = os.WriteFile(resolvers, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	query.Fields[0].Args = append(query.Fields[0].Args, &codegen.FieldArgument{
+		ArgumentDefinition: &ast.ArgumentDefinition{Name: "name"},
+		VarName:            "name",
+		TypeReference:      &config.TypeReference{GO: types.NewPointer(types.Typ[types.String])},
+	})
+
+	if err = splitter.GenerateCode(&codegen.Data{Config: genCfg, Objects: codegen.Objects{query}}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ = os.ReadFile(resolvers); !strings.Contains(string(b), "\treturn r.Users.Query().GetUser(ctx, id, name)\n") {
+		t.Errorf("expected the delegation to pass the new argument, got:\n%s", b)
+	}
+
+	// the root package and the package of the prefix compile together
+	if _, err := exec.LookPath("go"); err == nil {
+		cmd := exec.Command("go", "build", "./...")
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("expected the packages to build, got %v:\n%s", err, out)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package types_splitter_plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen/config"
+)
+
+func Test_configureResolver(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver config.ResolverConfig
+		suffix   string
+		strict   bool
+		packages bool
+		layout   string
+		model    config.PackageConfig
+		expected config.ResolverConfig
+		warning  string
+		err      string
+	}{
+		{
+			name:     "no resolver",
+			resolver: config.ResolverConfig{},
+			expected: config.ResolverConfig{},
+		},
+		{
+			name:     "default suffix",
+			resolver: config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph/resolvers"},
+			expected: config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph/resolvers", FilenameTemplate: "{name}.resolvers.go"},
+		},
+		{
+			name:     "custom suffix",
+			resolver: config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph"},
+			suffix:   "_resolver",
+			expected: config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph", FilenameTemplate: "{name}_resolver.go"},
+		},
+		{
+			name:     "custom filename template",
+			resolver: config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph", FilenameTemplate: "{name}.resolver.go"},
+			suffix:   "_resolver",
+			expected: config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph", FilenameTemplate: "{name}.resolver.go"},
+			warning:  `resolver_suffix "_resolver" is ignored, the resolver filename_template "{name}.resolver.go" is used`,
+		},
+		{
+			name:     "filename template without name",
+			resolver: config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph", FilenameTemplate: "resolvers.go"},
+			err:      `resolver filename_template "resolvers.go" must contain {name}`,
+		},
+		{
+			name:     "single file",
+			resolver: config.ResolverConfig{Layout: config.LayoutSingleFile, Filename: "graph/resolver.go"},
+			expected: config.ResolverConfig{Layout: config.LayoutSingleFile, Filename: "graph/resolver.go"},
+			warning:  "resolver layout single-file generates every resolver in graph/resolver.go, only the schema is split",
+		},
+		{
+			name:     "single file in strict mode",
+			resolver: config.ResolverConfig{Layout: config.LayoutSingleFile, Filename: "graph/resolver.go"},
+			strict:   true,
+			err:      "resolver layout single-file generates every resolver in graph/resolver.go",
+		},
+		{
+			name:     "forced layout",
+			resolver: config.ResolverConfig{Layout: config.LayoutSingleFile, Filename: "graph/resolver.go"},
+			layout:   "follow-schema",
+			expected: config.ResolverConfig{Layout: config.LayoutFollowSchema, Filename: "graph/resolver.go", DirName: "graph", FilenameTemplate: "{name}.resolvers.go"},
+		},
+		{
+			name:     "packages with a single file",
+			resolver: config.ResolverConfig{Layout: config.LayoutSingleFile, Filename: "graph/resolver.go"},
+			packages: true,
+			err:      "resolver_packages requires the resolver layout follow-schema, got single-file",
+		},
+		{
+			name:     "packages with the models in the resolver package",
+			resolver: config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph"},
+			packages: true,
+			model:    config.PackageConfig{Filename: "graph/models_gen.go"},
+			err:      "resolver_packages requires the models in another package than the resolvers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splitter := &TypesSplitterPlugin{
+				cfg:    &SplitterConfig{ResolverSuffix: tt.suffix, Strict: tt.strict, ResolverPackages: tt.packages, ResolverLayout: tt.layout},
+				genCfg: &config.Config{Resolver: tt.resolver, Model: tt.model},
+			}
+
+			err := splitter.configureResolver()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if splitter.genCfg.Resolver != tt.expected {
+				t.Errorf("expected resolver config %+v, got %+v", tt.expected, splitter.genCfg.Resolver)
+			}
+
+			warnings := strings.Join(splitter.Warnings(), "\n")
+			if (tt.warning == "" && warnings != "") || !strings.Contains(warnings, tt.warning) {
+				t.Errorf("expected warning %q, got %q", tt.warning, warnings)
+			}
+		})
+	}
+}
+
+func Test_MutateConfig_ResolversMode(t *testing.T) {
+	cfg, err := loadConfig("./test_data/gqlgen_plugins.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Mode = ModeResolvers
+
+	original := getTestGenConfig(t)
+	genCfg := getTestGenConfig(t)
+	if err = (&TypesSplitterPlugin{cfg: cfg}).MutateConfig(genCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// the sources are left as they were loaded
+	if len(genCfg.Sources) != len(original.Sources) {
+		t.Fatalf("expected %d sources, got %d", len(original.Sources), len(genCfg.Sources))
+	}
+	for i, src := range genCfg.Sources {
+		if src.Name != original.Sources[i].Name || src.Input != original.Sources[i].Input {
+			t.Errorf("expected source %s to be unchanged, got %s:\n%s", original.Sources[i].Name, src.Name, src.Input)
+		}
+	}
+
+	// moved fields point to their split source, after which gqlgen names their resolver file, while the fields
+	// left in their source keep their position
+	tests := map[string]string{
+		"getUser":          "users.queries.graphql",
+		"getPostsByEditor": "posts.queries.graphql",
+		"node":             "queries.graphql",
+		"nodes":            "queries.graphql",
+	}
+	for name, expected := range tests {
+		pos := genCfg.Schema.Query.Fields.ForName(name).Position
+		if pos.Src.Name != expected {
+			t.Errorf("%s: expected source %s, got %s", name, expected, pos.Src.Name)
+		}
+
+		if expected == "queries.graphql" {
+			originalPos := original.Schema.Query.Fields.ForName(name).Position
+			if pos.Start != originalPos.Start || pos.End != originalPos.End || pos.Line != originalPos.Line {
+				t.Errorf("%s: expected position %d:%d, got %d:%d", name, originalPos.Start, originalPos.End, pos.Start, pos.End)
+			}
+		}
+	}
+
+	_, err = readConfig(strings.NewReader(`
+types_splitter:
+  mode: resolvers
+  write_sources: true
+  types:
+    - name: User
+      prefix: users
+`))
+	if err == nil || !strings.Contains(err.Error(), "write_sources can't be used with mode resolvers") {
+		t.Errorf("expected a write_sources error, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	goast "go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/99designs/gqlgen/codegen/templates"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// resolverTestsTemplate is the template of the test file of the resolvers of a new source, with a table-driven
+// test per resolver method.
+const resolverTestsTemplate = `{{ reserveImport "context" }}
+{{ reserveImport "reflect" }}
+{{ reserveImport "testing" }}
+
+{{ range $field := .Fields -}}
+func {{ testName $field }}(t *testing.T) {
+	tests := []struct {
+		name string
+		{{- if not $field.Object.Root }}
+		obj  {{ $field.Object.Reference | ref }}
+		{{- end }}
+		{{- if $field.Args }}
+		args struct {
+			{{- range $arg := $field.Args }}
+			{{ $arg.VarName }} {{ $arg.TypeReference.GO | ref }}
+			{{- end }}
+		}
+		{{- end }}
+		want    {{ if $field.Object.Stream }}<-chan {{ end }}{{ $field.TypeReference.GO | ref }}
+		wantErr bool
+	}{
+		// TODO: add test cases
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &{{ lcFirst $field.Object.Name }}{{ ucFirst $.Type }}{&{{ $.Type }}{ {{- with domainOf $field }}Domains: Domains{ {{- .Field }}: &{{ lookupImport .ImportPath }}.Resolver{}}{{ end -}} }}
+			got, err := r.{{ $field.GoFieldName }}(context.Background(){{ if not $field.Object.Root }}, tt.obj{{ end }}{{ range $arg := $field.Args }}, tt.args.{{ $arg.VarName }}{{ end }})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("{{ $field.GoFieldName }}() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("{{ $field.GoFieldName }}() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+{{ end -}}
+`
+
+// generateResolverTests generates a test file next to the resolvers file of each new source, with a table-driven
+// test per resolver method of the fields moved to the source. Existing test files are never overwritten, and the
+// methods already tested in the resolver package are left out.
+func (s *TypesSplitterPlugin) generateResolverTests(data *codegen.Data) error {
+	resolver := data.Config.Resolver
+	if !s.cfg.ResolverTests || s.dryRun || !resolver.IsDefined() || resolver.Layout != config.LayoutFollowSchema {
+		return nil
+	}
+
+	tested, err := testFuncs(resolver.Dir())
+	if err != nil {
+		return err
+	}
+
+	// with resolver_packages, the resolvers delegate to the resolver of their domain, which the tests initialise
+	domains := make(map[string]*resolverDomain)
+	if s.cfg.ResolverPackages {
+		for _, domain := range s.resolverDomains(data) {
+			domains[domain.Prefix] = domain
+		}
+	}
+	domainOf := func(f *codegen.Field) *resolverDomain {
+		return domains[s.resolverPrefix(f)]
+	}
+
+	fields := make(map[string][]*codegen.Field)
+	for _, o := range data.Objects {
+		if o.Kind == ast.InputObject {
+			continue
+		}
+
+		for _, f := range o.Fields {
+			if !f.IsResolver || f.Position == nil || f.Position.Src == nil || s.newSources[f.Position.Src.Name] == nil {
+				continue
+			}
+			if tested[resolverTestName(f)] {
+				continue
+			}
+
+			filename := resolverFileName(resolver.Dir(), f.Position.Src.Name, resolverFilenameTemplate(resolver))
+			filename = strings.TrimSuffix(filename, ".go") + "_test.go"
+			fields[filename] = append(fields[filename], f)
+		}
+	}
+
+	for _, filename := range sortedKeys(fields) {
+		if _, err = os.Stat(filename); err == nil {
+			continue
+		}
+
+		err = templates.Render(templates.Options{
+			PackageName: resolver.Package,
+			FileNotice:  "// This file will not be regenerated automatically.\n//\n// It contains a test per resolver of the fields split to its source, add the test cases here.",
+			Filename:    filename,
+			Data: struct {
+				Type   string
+				Fields []*codegen.Field
+			}{resolver.Type, fields[filename]},
+			Funcs:    map[string]any{"testName": resolverTestName, "domainOf": domainOf},
+			Packages: data.Config.Packages,
+			Template: resolverTestsTemplate,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate the resolver tests %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+// resolverTestName returns the name of the test of the resolver of the field, eg. TestQueryResolver_GetUser.
+func resolverTestName(f *codegen.Field) string {
+	return "Test" + templates.UcFirst(f.Object.Name) + "Resolver_" + f.GoFieldName
+}
+
+// testFuncs returns the names of the functions declared in the test files of the given directory.
+func testFuncs(dir string) (map[string]bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test files: %w", err)
+	}
+
+	funcs := make(map[string]bool)
+	for _, p := range paths {
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read test file: %w", err)
+		}
+
+		f, err := parseGoFile(p, src)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.file.Decls {
+			if fn, ok := decl.(*goast.FuncDecl); ok && fn.Recv == nil {
+				funcs[fn.Name.Name] = true
+			}
+		}
+	}
+
+	return funcs, nil
+}
@@ -0,0 +1,172 @@
+package types_splitter_plugin
+
+import (
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func Test_GenerateCode_ResolverTests(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		// resolver is the resolver the tests create
+		resolver string
+	}{
+		{
+			name:     "resolvers",
+			resolver: "&Resolver{}",
+		},
+		{
+			// the tests initialise the resolver of the domain the resolvers delegate to
+			name:     "resolver packages",
+			rules:    "  resolver_packages: true\n",
+			resolver: "&Resolver{Domains: Domains{Users: &users.Resolver{}}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			graphDir := filepath.Join(dir, "graph")
+
+			const editorsTest = "package graph\n\n// hand-written\n"
+
+			// the resolver package as generated by gqlgen, with tests written before the plugin runs
+			files := map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.20\n",
+				"graph/resolver.go": `package graph
+
+type Resolver struct{}
+`,
+				"graph/users.queries.resolvers.go": `package graph
+
+import "context"
+
+func (r *queryResolver) GetUser(ctx context.Context, id string, name *string) (string, error) {
+	return id, nil
+}
+
+func (r *queryResolver) GetPost(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+func (r *queryResolver) GetEditor(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+func (r *Resolver) Query() *queryResolver { return &queryResolver{r} }
+
+type queryResolver struct{ *Resolver }
+`,
+				"graph/legacy_test.go":                    "package graph\n\nimport \"testing\"\n\nfunc TestQueryResolver_GetPost(t *testing.T) {}\n",
+				"graph/editors.queries.resolvers_test.go": editorsTest,
+			}
+
+			for name, content := range files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			genCfg := &config.Config{
+				Resolver: config.ResolverConfig{
+					Layout:           config.LayoutFollowSchema,
+					DirName:          graphDir,
+					Filename:         filepath.Join(graphDir, "resolver.go"),
+					FilenameTemplate: "{name}.resolvers.go",
+					Package:          "graph",
+					Type:             "Resolver",
+				},
+			}
+			// the packages cache of gqlgen is internal, a zero one is created for the templates to render
+			reflect.ValueOf(&genCfg.Packages).Elem().Set(reflect.New(reflect.TypeOf(genCfg.Packages).Elem()))
+
+			users := &ast.Source{Name: "users.queries.graphql"}
+			editors := &ast.Source{Name: "editors.queries.graphql"}
+			queries := &ast.Source{Name: "queries.graphql"}
+			query := &codegen.Object{
+				Definition: &ast.Definition{Name: "Query", Position: &ast.Position{Src: queries}},
+				Root:       true,
+			}
+			field := func(name, goName string, src *ast.Source, args ...*codegen.FieldArgument) *codegen.Field {
+				return &codegen.Field{
+					FieldDefinition: &ast.FieldDefinition{Name: name, Position: &ast.Position{Src: src}},
+					Object:          query,
+					GoFieldName:     goName,
+					IsResolver:      true,
+					TypeReference:   &config.TypeReference{GO: types.Typ[types.String]},
+					Args:            args,
+				}
+			}
+			query.Fields = []*codegen.Field{
+				field("getUser", "GetUser", users,
+					&codegen.FieldArgument{ArgumentDefinition: &ast.ArgumentDefinition{Name: "id"}, VarName: "id", TypeReference: &config.TypeReference{GO: types.Typ[types.String]}},
+					&codegen.FieldArgument{ArgumentDefinition: &ast.ArgumentDefinition{Name: "name"}, VarName: "name", TypeReference: &config.TypeReference{GO: types.NewPointer(types.Typ[types.String])}},
+				),
+				// already tested
+				field("getPost", "GetPost", users),
+				// the test file of its source already exists
+				field("getEditor", "GetEditor", editors),
+				// not split
+				field("node", "Node", queries),
+			}
+
+			splitter := &TypesSplitterPlugin{
+				cfg:        getTestConfig(t, "types_splitter:\n  resolver_tests: true\n"+tt.rules+"  queries:\n    - prefix: users\n      matches: [user]\n"),
+				newSources: SourcesMap{users.Name: &Source{}, editors.Name: &Source{}},
+				placements: map[string]string{"Query.getUser": "users", "Query.getPost": "users", "Query.getEditor": "editors"},
+			}
+			if err := splitter.GenerateCode(&codegen.Data{Config: genCfg, Objects: codegen.Objects{query}}); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := os.ReadFile(filepath.Join(graphDir, "users.queries.resolvers_test.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			generated := string(b)
+			for _, expected := range []string{
+				"func TestQueryResolver_GetUser(t *testing.T) {",
+				"\t\targs struct {\n\t\t\tid   string\n\t\t\tname *string\n\t\t}\n",
+				"\t\t\tr := &queryResolver{" + tt.resolver + "}\n\t\t\tgot, err := r.GetUser(context.Background(), tt.args.id, tt.args.name)\n",
+			} {
+				if !strings.Contains(generated, expected) {
+					t.Errorf("expected the tests to contain:\n%s\ngot:\n%s", expected, generated)
+				}
+			}
+			for _, unexpected := range []string{"GetPost", "GetEditor", "Node"} {
+				if strings.Contains(generated, unexpected) {
+					t.Errorf("expected no test of %s, got:\n%s", unexpected, generated)
+				}
+			}
+
+			if b, _ = os.ReadFile(filepath.Join(graphDir, "editors.queries.resolvers_test.go")); string(b) != editorsTest {
+				t.Errorf("expected the existing test file to be left as is, got:\n%s", b)
+			}
+			if _, err = os.Stat(filepath.Join(graphDir, "queries.resolvers_test.go")); !os.IsNotExist(err) {
+				t.Errorf("expected no test file for the original source, got %v", err)
+			}
+
+			// the generated tests compile and pass without test cases
+			if _, err := exec.LookPath("go"); err == nil {
+				cmd := exec.Command("go", "test", "./...")
+				cmd.Dir = dir
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Errorf("expected the tests to pass, got %v:\n%s", err, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// JSONSchemaID is the id of the published JSON Schema of the config file.
+	JSONSchemaID = "https://raw.githubusercontent.com/nicored/types_splitter_plugin/main/types_splitter.schema.json"
+
+	// prefixPattern is the JSON Schema equivalent of validatePrefix.
+	prefixPattern = `^(?!.*\.\.)[^./\\<>:"|?*\s]([^/\\<>:"|?*\s]*[^./\\<>:"|?*\s])?$`
+
+	// filenameTemplatePattern is the JSON Schema check of the {prefix} placeholder of validateFilenameTemplate.
+	filenameTemplatePattern = `\{prefix(_dir)?\}`
+)
+
+// Diagnostic is a problem found in the config file.
+type Diagnostic struct {
+	// Line is the line of the problem in the config file, starting at 1.
+	Line int
+	// Column is the column of the problem in the config file, starting at 1, or 0 if unknown.
+	Column int
+	// Message describes the problem.
+	Message string
+}
+
+// String returns the diagnostic formatted as line:column: message.
+func (d Diagnostic) String() string {
+	if d.Column == 0 {
+		return fmt.Sprintf("%d: %s", d.Line, d.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+}
+
+// jsonSchema is the subset of JSON Schema used to describe the config.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	MinItems             int                    `json:"minItems,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema of the config file, generated from PluginsCfg.
+func JSONSchema() ([]byte, error) {
+	schema := schemaOf(reflect.TypeOf(PluginsCfg{}))
+	schema.Schema = "http://json-schema.org/draft-07/schema#"
+	schema.ID = JSONSchemaID
+	schema.Title = "gqlgen plugins config"
+	// the config file may be shared with other plugins
+	schema.AdditionalProperties = true
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// schemaOf returns the JSON Schema of the given type.
+func schemaOf(typ reflect.Type) *jsonSchema {
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return schemaOf(typ.Elem())
+	case reflect.Struct:
+		schema := &jsonSchema{
+			Type:                 "object",
+			Properties:           map[string]*jsonSchema{},
+			AdditionalProperties: false,
+		}
+
+		for _, field := range configFields(typ) {
+			prop := schemaOf(field.Type)
+			prop.Description = field.Tag.Get("desc")
+
+			if enum := field.Tag.Get("enum"); enum != "" {
+				prop.Enum = strings.Split(enum, ",")
+			}
+
+			switch field.Tag.Get("validate") {
+			case "prefix":
+				prop.Pattern = prefixPattern
+			case "regex":
+				prop.Items.Format = "regex"
+			case "filename_template":
+				prop.Pattern = filenameTemplatePattern
+			}
+
+			if field.Tag.Get("required") == "true" {
+				schema.Required = append(schema.Required, yamlName(field))
+				if prop.Type == "array" {
+					prop.MinItems = 1
+				}
+			}
+
+			schema.Properties[yamlName(field)] = prop
+		}
+
+		return schema
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: schemaOf(typ.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: schemaOf(typ.Elem())}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	default:
+		return &jsonSchema{Type: "string"}
+	}
+}
+
+// configFields returns the fields of the given struct that are read from the config file.
+func configFields(typ reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || yamlName(field) == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// yamlName returns the name of the field in the config file.
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+var yamlErrLineRegex = regexp.MustCompile(`line (\d+): (.*)`)
+
+// ValidateConfig validates the structure of the config file and returns every problem found,
+// instead of stopping at the first one.
+func ValidateConfig(cfgFile io.Reader) []Diagnostic {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(cfgFile).Decode(&doc); err != nil {
+		if err == io.EOF {
+			return []Diagnostic{{Line: 1, Column: 1, Message: "empty config"}}
+		}
+		return yamlErrDiagnostics(err)
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return []Diagnostic{nodeDiagnostic(root, "expected a mapping")}
+	}
+
+	// the config file may be shared with other plugins
+	v := &cfgValidator{}
+	v.validateStruct(root, reflect.TypeOf(PluginsCfg{}), true)
+
+	if version := mappingValue(root, "version"); version != nil {
+		if n, err := strconv.Atoi(version.Value); err == nil && (n < 1 || n > ConfigVersion) {
+			v.add(version, "unsupported config version %d, max version is %d", n, ConfigVersion)
+		}
+	}
+
+	if splitter := mappingValue(root, "types_splitter"); splitter != nil && splitter.Kind == yaml.MappingNode {
+		if len(nodeContent(mappingValue(splitter, "types"))) == 0 && len(nodeContent(mappingValue(splitter, "queries"))) == 0 {
+			v.add(splitter, "no type or query configs defined")
+		}
+	}
+
+	return v.diagnostics
+}
+
+// cfgValidator walks the config nodes and collects diagnostics.
+type cfgValidator struct {
+	diagnostics []Diagnostic
+}
+
+func (v *cfgValidator) add(node *yaml.Node, format string, args ...any) {
+	v.diagnostics = append(v.diagnostics, nodeDiagnostic(node, fmt.Sprintf(format, args...)))
+}
+
+// validateStruct validates a mapping node against a config struct.
+// Unknown keys are reported unless allowUnknown is set.
+func (v *cfgValidator) validateStruct(node *yaml.Node, typ reflect.Type, allowUnknown bool) {
+	if node.Kind != yaml.MappingNode {
+		v.add(node, "expected a mapping")
+		return
+	}
+
+	fields := map[string]reflect.StructField{}
+	for _, field := range configFields(typ) {
+		fields[yamlName(field)] = field
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		field, ok := fields[key.Value]
+		if !ok {
+			if !allowUnknown {
+				v.add(key, "unknown field %q", key.Value)
+			}
+			continue
+		}
+
+		v.validateValue(value, field)
+	}
+
+	for _, field := range configFields(typ) {
+		if field.Tag.Get("required") != "true" {
+			continue
+		}
+
+		if value := mappingValue(node, yamlName(field)); value == nil || value.Tag == "!!null" {
+			v.add(node, "missing required field %q", yamlName(field))
+		} else if value.Kind == yaml.SequenceNode && len(value.Content) == 0 {
+			v.add(value, "%q must not be empty", yamlName(field))
+		}
+	}
+}
+
+// validateValue validates the node of a struct field.
+func (v *cfgValidator) validateValue(node *yaml.Node, field reflect.StructField) {
+	typ := field.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		v.validateStruct(node, typ, false)
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.add(node, "%q must be a list", yamlName(field))
+			return
+		}
+
+		for _, item := range node.Content {
+			if typ.Elem().Kind() == reflect.Struct {
+				v.validateStruct(item, typ.Elem(), false)
+				continue
+			}
+			v.validateScalar(item, typ.Elem(), field)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.add(node, "%q must be a mapping", yamlName(field))
+			return
+		}
+
+		for i := 1; i < len(node.Content); i += 2 {
+			v.validateScalar(node.Content[i], typ.Elem(), field)
+		}
+	default:
+		v.validateScalar(node, typ, field)
+	}
+}
+
+// validateScalar validates a scalar node, and its value when the field has a validate or an enum tag.
+func (v *cfgValidator) validateScalar(node *yaml.Node, typ reflect.Type, field reflect.StructField) {
+	name := yamlName(field)
+
+	if node.Kind != yaml.ScalarNode {
+		v.add(node, "%q must be a %s", name, schemaOf(typ).Type)
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		if node.Tag != "!!bool" {
+			v.add(node, "%q must be a boolean, got %q", name, node.Value)
+		}
+		return
+	case reflect.Int:
+		if _, err := strconv.Atoi(node.Value); err != nil {
+			v.add(node, "%q must be an integer, got %q", name, node.Value)
+		}
+		return
+	}
+
+	if err := checkEnum(field, node.Value); err != nil {
+		v.add(node, "%s", err)
+	}
+
+	switch field.Tag.Get("validate") {
+	case "prefix":
+		if err := validatePrefix(node.Value); err != nil {
+			v.add(node, "%s", err)
+		}
+	case "filename_template":
+		if err := validateFilenameTemplate(node.Value); err != nil {
+			v.add(node, "%s", err)
+		}
+	case "regex":
+		if strings.TrimSpace(node.Value) == "" {
+			v.add(node, "empty match regex")
+		} else if _, err := regexp.Compile(node.Value); err != nil {
+			v.add(node, "invalid match regex %q: %s", node.Value, err)
+		}
+	}
+}
+
+// mappingValue returns the value of the given key in a mapping node, or nil if not found.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// nodeContent returns the content of the given node, or nil if the node is nil.
+func nodeContent(node *yaml.Node) []*yaml.Node {
+	if node == nil {
+		return nil
+	}
+	return node.Content
+}
+
+func nodeDiagnostic(node *yaml.Node, msg string) Diagnostic {
+	return Diagnostic{Line: node.Line, Column: node.Column, Message: msg}
+}
+
+// yamlErrDiagnostics converts a yaml parsing error into diagnostics.
+func yamlErrDiagnostics(err error) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if m := yamlErrLineRegex.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			diagnostics = append(diagnostics, Diagnostic{Line: n, Message: m[2]})
+		}
+	}
+
+	if len(diagnostics) == 0 {
+		diagnostics = append(diagnostics, Diagnostic{Line: 1, Message: err.Error()})
+	}
+
+	return diagnostics
+}
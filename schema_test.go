@@ -0,0 +1,124 @@
+package types_splitter_plugin
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update-schema", false, "update types_splitter.schema.json")
+
+func Test_JSONSchema(t *testing.T) {
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *updateSchema {
+		if err = os.WriteFile("types_splitter.schema.json", schema, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	published, err := os.ReadFile("types_splitter.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(schema, published) {
+		t.Error("types_splitter.schema.json is out of sync with the config structs, run: go test -run Test_JSONSchema -update-schema")
+	}
+}
+
+func Test_ValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name: "valid config",
+			config: `
+other_plugin:
+  enabled: true
+types_splitter:
+  types:
+    - name: User
+      prefix: users
+  queries:
+    - prefix: posts
+      matches:
+        - post
+`,
+			want: nil,
+		},
+		{
+			name: "every problem is reported",
+			config: `types_splitter:
+  strict: maybe
+  type:
+    - name: User
+  types:
+    - name: User
+    - name: Post
+      prefix: ../posts
+  queries:
+    - prefix: posts
+      matches:
+        - "post("
+        - ""
+    - prefix: users
+      matches: []
+`,
+			want: []string{
+				`2:11: "strict" must be a boolean, got "maybe"`,
+				`3:3: unknown field "type"`,
+				`6:7: missing required field "prefix"`,
+				`8:15: prefix "../posts" must not start or end with a dot`,
+				"12:11: invalid match regex \"post(\": error parsing regexp: missing closing ): `post(`",
+				`13:11: empty match regex`,
+				`15:16: "matches" must not be empty`,
+			},
+		},
+		{
+			name:   "unknown enum value",
+			config: "types_splitter:\n  format: pretty\n  types:\n    - name: User\n      prefix: users\n",
+			want:   []string{`2:11: "format" must be one of template, canonical, got "pretty"`},
+		},
+		{
+			name:   "invalid filename template",
+			config: "types_splitter:\n  filename_template:\n    type: \"{original}{ext}\"\n  types:\n    - name: User\n      prefix: users\n",
+			want:   []string{`3:11: filename template "{original}{ext}" must contain {prefix} or {prefix_dir}`},
+		},
+		{
+			name:   "missing splitter config",
+			config: "other_plugin: {}\n",
+			want:   []string{`1:1: missing required field "types_splitter"`},
+		},
+		{
+			name:   "no rules",
+			config: "types_splitter:\n  strict: true\n",
+			want:   []string{`2:3: no type or query configs defined`},
+		},
+		{
+			name:   "syntax error",
+			config: "types_splitter:\n  types: [\n",
+			want:   []string{`2: did not find expected node content`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range ValidateConfig(strings.NewReader(tt.config)) {
+				got = append(got, d.String())
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ValidateConfig() =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package types_splitter_plugin
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// SourceMap maps the lines of the sources of the split back to the original sources they come from, so that
+// errors reported by gqlgen point to the files developers edit.
+type SourceMap struct {
+	// mappings are the mappings of each source, sorted by line
+	mappings map[string][]sourceMapping
+}
+
+// sourceMapping maps consecutive lines of a source to an original source.
+type sourceMapping struct {
+	line, lines int
+
+	original     string
+	originalLine int
+	// originalColumn is the column of the lines that aren't mapped exactly
+	originalColumn int
+	// exact is whether each line maps to its own original line and column, or all of them to the original
+	// line and column, eg. when the source was reformatted
+	exact bool
+}
+
+// SourceMap returns the source map of the last split. It's empty when the sources are written to disk,
+// as they are then the original sources.
+func (s *TypesSplitterPlugin) SourceMap() *SourceMap {
+	return s.sourceMap
+}
+
+// Resolve returns the original source, line and column of the given line and column of a source of the split,
+// and whether they were found. Lines that weren't moved or generated by a template aren't found.
+func (m *SourceMap) Resolve(source string, line, column int) (string, int, int, bool) {
+	if m == nil {
+		return "", 0, 0, false
+	}
+
+	mappings := m.mappings[source]
+	i := sort.Search(len(mappings), func(i int) bool {
+		return mappings[i].line+mappings[i].lines > line
+	})
+	if i == len(mappings) || mappings[i].line > line {
+		return "", 0, 0, false
+	}
+
+	mapping := mappings[i]
+	if !mapping.exact {
+		return mapping.original, mapping.originalLine, mapping.originalColumn, true
+	}
+
+	return mapping.original, mapping.originalLine + line - mapping.line, column, true
+}
+
+// RewriteError rewrites the file and the locations of the gqlerror.Error or gqlerror.List wrapped in err to
+// point to the original sources, and returns err. Locations that can't be resolved are left unchanged.
+func (m *SourceMap) RewriteError(err error) error {
+	var list gqlerror.List
+	if errors.As(err, &list) {
+		for _, gqlErr := range list {
+			m.rewriteError(gqlErr)
+		}
+		return err
+	}
+
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		m.rewriteError(gqlErr)
+	}
+
+	return err
+}
+
+// rewriteError rewrites the file and locations of the error, the file being the original source of its
+// first location.
+func (m *SourceMap) rewriteError(err *gqlerror.Error) {
+	file, _ := err.Extensions["file"].(string)
+	if file == "" {
+		return
+	}
+
+	for i, loc := range err.Locations {
+		original, line, column, ok := m.Resolve(file, loc.Line, loc.Column)
+		if !ok {
+			continue
+		}
+
+		if i == 0 {
+			err.SetFile(original)
+		}
+		err.Locations[i] = gqlerror.Location{Line: line, Column: column}
+	}
+}
+
+// keepSources removes the mappings of the given sources, whose input and positions are restored as they were
+// loaded in resolvers mode.
+func (m *SourceMap) keepSources(sources []*ast.Source) {
+	for _, src := range sources {
+		delete(m.mappings, src.Name)
+	}
+}
+
+// add adds a mapping to the source map.
+func (m *SourceMap) add(source string, mapping sourceMapping) {
+	m.mappings[source] = append(m.mappings[source], mapping)
+}
+
+// buildSourceMap builds the source map of the split once the sources of the config are final.
+func (s *TypesSplitterPlugin) buildSourceMap() *SourceMap {
+	m := &SourceMap{mappings: make(map[string][]sourceMapping)}
+	if s.cfg.WriteSources {
+		return m
+	}
+
+	// the lines left in the original sources are found by diffing them with their original input
+	for _, name := range sortedKeys(s.sources) {
+		src := s.sources[name]
+		if !containsSource(s.genCfg.Sources, src.Source) || src.Input == s.originalInputs[name] {
+			continue
+		}
+
+		original, actual := splitLines(s.originalInputs[name]), splitLines(src.Input)
+		for _, op := range diffLines(original, actual) {
+			if op.kind == diffEqual {
+				m.add(name, sourceMapping{line: op.b + 1, lines: 1, original: name, originalLine: op.a + 1, exact: true})
+			}
+		}
+	}
+
+	// moved fields and types are copied as is by the templates, so their lines map one to one, unless
+	// the source was reformatted
+	for _, name := range sortedKeys(s.newSources) {
+		newSrc := s.newSources[name]
+
+		for _, field := range newSrc.Fields {
+			m.addMoved(field, field.OriginalPosition, field.Content, newSrc.formatted)
+		}
+
+		for _, def := range newSrc.Types {
+			m.addMoved(def, def.OriginalPosition, def.Content, newSrc.formatted)
+			if newSrc.formatted {
+				for _, field := range def.Fields {
+					m.addMoved(field, field.OriginalPosition, field.Content, true)
+				}
+			}
+		}
+	}
+
+	for source, mappings := range m.mappings {
+		sort.SliceStable(mappings, func(i, j int) bool {
+			return mappings[i].line < mappings[j].line
+		})
+		m.mappings[source] = mappings
+	}
+
+	return m
+}
+
+// addMoved maps the lines of a moved field or type to its original position.
+func (m *SourceMap) addMoved(pos Positioner, original ast.Position, content string, formatted bool) {
+	if pos.Pos().Src == nil || original.Src == nil {
+		return
+	}
+
+	if formatted {
+		m.add(pos.Pos().Src.Name, sourceMapping{
+			line:           pos.Pos().Line,
+			lines:          1,
+			original:       original.Src.Name,
+			originalLine:   original.Line,
+			originalColumn: original.Column,
+		})
+		return
+	}
+
+	// the content starts at the actual position, which is above the position when there is a description
+	m.add(pos.Pos().Src.Name, sourceMapping{
+		line:         pos.ActualPos().Line,
+		lines:        countLines(content) + 1,
+		original:     original.Src.Name,
+		originalLine: original.Line - (pos.Pos().Line - pos.ActualPos().Line),
+		exact:        true,
+	})
+}
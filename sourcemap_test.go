@@ -0,0 +1,63 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func Test_SourceMap(t *testing.T) {
+	for _, format := range []string{FormatTemplate, FormatCanonical} {
+		t.Run(format, func(t *testing.T) {
+			cfg, err := loadConfig("./test_data/gqlgen_plugins.yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.Format = format
+
+			genCfg := getTestGenConfig(t)
+			splitter := &TypesSplitterPlugin{cfg: cfg}
+			if err = splitter.MutateConfig(genCfg); err != nil {
+				t.Fatal(err)
+			}
+
+			schema, err := gqlparser.LoadSchema(genCfg.Sources...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the fields are resolved to the positions reported for the original sources
+			original, err := gqlparser.LoadSchema(getTestSources(t, false)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// getPostsByEditor and getUser are moved to new sources, node and nodes are left in queries.graphql
+			// below the moved getUser and getPost
+			tests := []string{"getPostsByEditor", "getUser", "node", "nodes"}
+
+			for _, name := range tests {
+				field := schema.Query.Fields.ForName(name)
+				originalPos := original.Query.Fields.ForName(name).Position
+				expected := fmt.Sprintf("%s:%d:%d", originalPos.Src.Name, originalPos.Line, originalPos.Column)
+
+				file, line, column, ok := splitter.SourceMap().Resolve(field.Position.Src.Name, field.Position.Line, field.Position.Column)
+				if !ok {
+					t.Errorf("%s: %s:%d not found", name, field.Position.Src.Name, field.Position.Line)
+					continue
+				}
+				if got := fmt.Sprintf("%s:%d:%d", file, line, column); got != expected {
+					t.Errorf("%s: expected %s, got %s", name, expected, got)
+				}
+			}
+
+			field := schema.Query.Fields.ForName("getPostsByEditor")
+			err = splitter.SourceMap().RewriteError(gqlerror.List{gqlerror.ErrorPosf(field.Position, "invalid field")})
+			if expected := "queries.graphql:29: invalid field\n"; err.Error() != expected {
+				t.Errorf("expected error %q, got %q", expected, err.Error())
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	_ "embed"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
 
 	"github.com/vektah/gqlparser/v2/ast"
@@ -17,8 +19,24 @@ var (
 	tmplQuery string
 	//go:embed tpl/type_definitions.go.tmpl
 	tmplObject string
+
+	defaultTemplates = &Templates{
+		QueryExtended: template.Must(template.New("query_extended").Parse(tmplQueryExtended)),
+		Query:         template.Must(template.New("query").Parse(tmplQuery)),
+		Object:        template.Must(template.New("object").Parse(tmplObject)),
+	}
 )
 
+// Templates are the templates used to generate the input of new sources.
+type Templates struct {
+	// QueryExtended generates sources extending a root type, executed with QueryViewData
+	QueryExtended *template.Template
+	// Query generates the source defining a root type, executed with QueryViewData
+	Query *template.Template
+	// Object generates sources of object types, executed with TypeViewData
+	Object *template.Template
+}
+
 // Source is a wrapper around ast.Source that implements Positioner
 type Source struct {
 	*ast.Source
@@ -27,7 +45,16 @@ type Source struct {
 	Fields FieldDefinitions
 	Types  Definitions
 
+	// Prefix is the prefix the source was created for, if it's a new source
+	Prefix string
+
 	isMainQuery bool
+	// formatted is whether the input was printed by the gqlparser formatter
+	formatted bool
+	// templates are the templates used to generate the input of the source, defaults are used if nil
+	templates *Templates
+	// moves are the definitions and fields moved to the source
+	moves []*ManifestMove
 }
 
 // Sources is a list of Source
@@ -50,14 +77,36 @@ func WrapSource(s *ast.Source) *Source {
 	}
 }
 
+// QueryViewData is the data used to execute the query templates.
 type QueryViewData struct {
-	Type   string
+	// Type is the name of the root type, eg. Query
+	Type string
+	// Fields are the fields of the root type in the source
 	Fields FieldDefinitions
+	// Source is the name of the generated source
+	Source string
+	// Prefix is the prefix the source was created for
+	Prefix string
+	// Rules are the configs that matched the fields, eg. queries[1], or lock for locked placements
+	Rules []string
+	// Originals are the names of the sources the fields were moved from
+	Originals []string
 }
 
+// TypeViewData is the data used to execute the object template.
 type TypeViewData struct {
-	Type  string
+	// Type is not set for object sources
+	Type string
+	// Types are the types in the source
 	Types Definitions
+	// Source is the name of the generated source
+	Source string
+	// Prefix is the prefix the source was created for
+	Prefix string
+	// Rules are the configs that matched the types, eg. types[0], or lock for locked placements
+	Rules []string
+	// Originals are the names of the sources the types were moved from
+	Originals []string
 }
 
 // NewSource creates a new Source
@@ -82,47 +131,34 @@ func NewSource(name string, sourceType SourceType) (*Source, error) {
 }
 
 func (s *Source) GenerateInput() (string, error) {
-	isQueryType := true
-	typeName := ""
-	switch s.typ {
-	case SourceQueryExtended:
-		typeName = "Query"
-	case SourceMutationExtended:
-		typeName = "Mutation"
-	case SourceSubscriptionExtended:
-		typeName = "Subscription"
-	case OriginalSource:
+	if s.typ == OriginalSource {
 		return "", fmt.Errorf("cannot generate input for original source")
-	default:
-		isQueryType = false
 	}
 
-	writer := bytes.Buffer{}
-	if isQueryType {
-		tmpl := tmplQueryExtended
-		if s.isMainQuery {
-			tmpl = tmplQuery
-		}
+	typeName := s.typeName()
+	isQueryType := typeName != ""
 
-		tpl, err := template.New("query_extended").Parse(tmpl)
-		if err != nil {
-			return "", err
-		}
+	rules, originals := s.provenance()
 
-		if err = tpl.Execute(&writer, QueryViewData{
-			Type:   typeName,
-			Fields: s.Fields,
+	writer := bytes.Buffer{}
+	if isQueryType {
+		if err := s.template().Execute(&writer, QueryViewData{
+			Type:      typeName,
+			Fields:    s.Fields,
+			Source:    s.Name,
+			Prefix:    s.Prefix,
+			Rules:     rules,
+			Originals: originals,
 		}); err != nil {
 			return "", err
 		}
 	} else {
-		tpl, err := template.New("object").Parse(tmplObject)
-		if err != nil {
-			return "", err
-		}
-
-		if err = tpl.Execute(&writer, TypeViewData{
-			Types: s.Types,
+		if err := s.template().Execute(&writer, TypeViewData{
+			Types:     s.Types,
+			Source:    s.Name,
+			Prefix:    s.Prefix,
+			Rules:     rules,
+			Originals: originals,
 		}); err != nil {
 			return "", err
 		}
@@ -133,6 +169,65 @@ func (s *Source) GenerateInput() (string, error) {
 	return writer.String(), nil
 }
 
+// template returns the template generating the input of the source.
+func (s *Source) template() *template.Template {
+	templates := s.templates
+	if templates == nil {
+		templates = defaultTemplates
+	}
+
+	switch {
+	case s.typeName() == "":
+		return templates.Object
+	case s.isMainQuery:
+		return templates.Query
+	default:
+		return templates.QueryExtended
+	}
+}
+
+// hasDefaultTemplate returns whether the input of the source is generated by one of the default templates,
+// rather than a custom one whose output is kept as is.
+func (s *Source) hasDefaultTemplate() bool {
+	tpl := s.template()
+	return tpl == defaultTemplates.Object || tpl == defaultTemplates.Query || tpl == defaultTemplates.QueryExtended
+}
+
+// typeName returns the name of the root type of the source, or an empty string if it's not a root type source.
+func (s *Source) typeName() string {
+	switch s.typ {
+	case SourceQueryExtended:
+		return "Query"
+	case SourceMutationExtended:
+		return "Mutation"
+	case SourceSubscriptionExtended:
+		return "Subscription"
+	default:
+		return ""
+	}
+}
+
+// provenance returns the rules that moved definitions and fields to the source, in the order they
+// were first used, and the sorted names of the sources they were moved from.
+func (s *Source) provenance() (rules []string, originals []string) {
+	seenRules := map[string]bool{}
+	seenOriginals := map[string]bool{}
+
+	for _, move := range s.moves {
+		if !seenRules[move.Rule] {
+			seenRules[move.Rule] = true
+			rules = append(rules, move.Rule)
+		}
+		if !seenOriginals[move.OriginalSource] {
+			seenOriginals[move.OriginalSource] = true
+			originals = append(originals, move.OriginalSource)
+		}
+	}
+	sort.Strings(originals)
+
+	return rules, originals
+}
+
 // FileName returns the name of the source
 func (s *Source) FileName() string {
 	return s.Name
@@ -153,3 +248,61 @@ func WrapSources(sources []*ast.Source) Sources {
 
 	return wrapped
 }
+
+// mergeSource appends the input of the new source to the original source, and returns the offset
+// of the appended input.
+func mergeSource(origSrc *Source, newSrc *Source) int {
+	origSrc.Input = strings.TrimRight(origSrc.Input, "\n") + "\n\n"
+	offset := len(origSrc.Input)
+	origSrc.Input += newSrc.Input
+
+	return offset
+}
+
+// locate points the given fields and types to the source, and relocates them to the position of their content
+// in the source input, starting at the given offset.
+func locate(src *ast.Source, fields FieldDefinitions, types Definitions, offset int) {
+	for _, field := range fields {
+		idx := strings.Index(src.Input[offset:], field.Content)
+		if idx < 0 {
+			continue
+		}
+
+		field.Position.Src = src
+		field.ActualPosition.Src = src
+		field.Relocate(offset + idx)
+		offset += idx + len(field.Content)
+	}
+
+	for _, def := range types {
+		idx := strings.Index(src.Input[offset:], def.Content)
+		if idx < 0 {
+			continue
+		}
+
+		def.Position.Src = src
+		def.ActualPosition.Src = src
+		def.Relocate(offset + idx)
+		offset += idx + len(def.Content)
+	}
+}
+
+// containsSource returns whether the list of sources contains the given source.
+func containsSource(sources []*ast.Source, src *ast.Source) bool {
+	for _, s := range sources {
+		if s == src {
+			return true
+		}
+	}
+	return false
+}
+
+// removeSource removes the given source from the list of sources.
+func removeSource(sources []*ast.Source, src *ast.Source) []*ast.Source {
+	for i, s := range sources {
+		if s == src {
+			return append(sources[:i], sources[i+1:]...)
+		}
+	}
+	return sources
+}
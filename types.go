@@ -58,6 +58,9 @@ type Definition struct {
 	// ActualPosition is the actual position of the definition in the source
 	// including documentation and its entire scope
 	ActualPosition *ast.Position
+
+	// OriginalPosition is the position of the definition in its original source, before the split
+	OriginalPosition ast.Position
 }
 
 func WrapDefinition(def *ast.Definition, typ DefObjectType) *Definition {
@@ -76,10 +79,11 @@ func WrapDefinition(def *ast.Definition, typ DefObjectType) *Definition {
 	}
 
 	return &Definition{
-		Definition:     def,
-		Content:        content,
-		typ:            typ,
-		ActualPosition: pos,
+		Definition:       def,
+		Content:          content,
+		typ:              typ,
+		ActualPosition:   pos,
+		OriginalPosition: *def.Position,
 	}
 }
 
@@ -144,6 +148,9 @@ type FieldDefinition struct {
 
 	Content        string
 	ActualPosition *ast.Position
+
+	// OriginalPosition is the position of the field in its original source, before the split
+	OriginalPosition ast.Position
 }
 
 func WrapFieldDefinition(field *ast.FieldDefinition, typ FieldDefType) *FieldDefinition {
@@ -162,10 +169,11 @@ func WrapFieldDefinition(field *ast.FieldDefinition, typ FieldDefType) *FieldDef
 	}
 
 	return &FieldDefinition{
-		FieldDefinition: field,
-		typ:             typ,
-		Content:         content,
-		ActualPosition:  pos,
+		FieldDefinition:  field,
+		typ:              typ,
+		Content:          content,
+		ActualPosition:   pos,
+		OriginalPosition: *field.Position,
 	}
 }
 
@@ -217,6 +225,41 @@ func (ds FieldDefinitions) FindAstField(field *ast.FieldDefinition) *FieldDefini
 	return nil
 }
 
+// Relocate moves the position of the definition and its fields to the given offset of its source,
+// where its content starts.
+func (d *Definition) Relocate(start int) {
+	for _, field := range d.Fields {
+		field.Position.Src = d.ActualPosition.Src
+		field.ActualPosition.Src = d.ActualPosition.Src
+		relocate(field, d.Content, d.ActualPosition.Start, start)
+	}
+	relocate(d, d.Content, d.ActualPosition.Start, start)
+}
+
+// Relocate moves the position of the field definition to the given offset of its source,
+// where its content starts.
+func (fd *FieldDefinition) Relocate(start int) {
+	relocate(fd, fd.Content, fd.ActualPosition.Start, start)
+}
+
+// relocate moves the position of the given Positioner, which is part of the given content, from the
+// content previous offset to its new offset in the source of the Positioner.
+func relocate(pos Positioner, content string, from, to int) {
+	src := pos.ActualPos().Src
+	line := countLines(src.Input[:to]) + 1
+
+	for _, p := range []*ast.Position{pos.ActualPos(), pos.Pos()} {
+		rel := p.Start - from
+		if rel < 0 || rel > len(content) {
+			continue
+		}
+
+		p.Line = line + countLines(content[:rel])
+		p.Start = to + rel
+		p.End += to - from
+	}
+}
+
 // shiftOffset shifts the position of the given Positioner by the given offset and lines
 func shiftOffset(pos Positioner, offset, lines int) {
 	pos.Pos().Line -= lines
@@ -284,7 +327,7 @@ func findCommentStart(input string, startPos int) (linesFound, start int) {
 	startPos = findPrevLineOffset(input, startPos)
 
 	// already starting with a comment
-	if len(input) >= startPos+3 && input[startPos:startPos+3] == `"""` {
+	if startPos >= 0 && len(input) >= startPos+3 && input[startPos:startPos+3] == `"""` {
 		return 0, start
 	}
 
@@ -359,13 +402,14 @@ func findLeftOverDirectives(input string, start int) (string, int) {
 	return "", start
 }
 
+// findPrevLineOffset returns the offset of the line break before the given offset, or -1 if it's on the first line.
 func findPrevLineOffset(input string, start int) int {
 	for i := start - 1; i >= 0; i-- {
 		if input[i] == '\n' {
 			return i
 		}
 	}
-	return start
+	return -1
 }
 
 func findNextLineOffset(input string, start int) int {
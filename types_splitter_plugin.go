@@ -2,17 +2,20 @@ package types_splitter_plugin
 
 import (
 	"fmt"
-	"path/filepath"
+	"io"
+	"log"
 	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/99designs/gqlgen/codegen"
 	"github.com/99designs/gqlgen/codegen/config"
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
 const (
-	PluginName      = "types_splitter"
+	PluginName = "types_splitter"
+	// ResolversSuffix is the default suffix of the resolver files, see SplitterConfig.ResolverSuffix.
 	ResolversSuffix = ".resolvers"
 )
 
@@ -58,6 +61,10 @@ type TypesSplitterPlugin struct {
 	sourcesDefs SourcesDefs
 	// sourcesFields is a map of existing source name to a list of FieldDefinition that are fields in that source
 	sourcesFields SourcesFields
+	// originalInputs is a map of existing source name to its input before the split
+	originalInputs map[string]string
+	// sourceHeaders is a map of existing source name to its header, for sources produced by a previous split
+	sourceHeaders map[string]*sourceHeader
 	// sourcesFieldsIndex is a map of existing source name to a map of FieldDefinition to index in the list of FieldDefinition
 	sourcesFieldsIndex map[string]map[*ast.FieldDefinition]int
 
@@ -65,30 +72,81 @@ type TypesSplitterPlugin struct {
 	newSources SourcesMap
 	// newSourcesDef is a map of new source name to a list of Definition that are defined in that source
 	newSourcesDef SourcesDefs
+
+	// placements is a map of root fields (eg. Query.getUser) and types to the prefix they were split into,
+	// or an empty string if they were left in their original source
+	placements map[string]string
+	// locked is the map of placements read from the lock file
+	locked map[string]string
+	// relock ignores the lock file and places every root field and type with the rules
+	relock bool
+
+	// dryRun computes the split on a copy of the config, leaving the gqlgen config untouched
+	dryRun bool
+	// dryRunOut is where the diff of the dry-run is written, if not nil
+	dryRunOut io.Writer
+	// diff is the unified diff of the schema changes of the last dry-run
+	diff string
+
+	// moves is the list of definitions and fields moved during the last run
+	moves []*ManifestMove
+	// manifest describes the changes made by the last run
+	manifest *Manifest
+
+	// resolverFiles are the resolver files as they were before gqlgen generated them
+	resolverFiles map[string]*goFile
+
+	// data is the data gqlgen generated the code of the last run with
+	data *codegen.Data
+
+	// sourceMap maps the sources of the last split back to the original sources
+	sourceMap *SourceMap
+
+	// templates are the templates used to generate new sources
+	templates *Templates
+
+	// warnings is a list of non-fatal issues found during the last run
+	warnings []string
+}
+
+// Option is an option of the TypesSplitterPlugin.
+type Option func(s *TypesSplitterPlugin)
+
+// WithRelock ignores the lock file and places every root field and type with the rules,
+// then rewrites the lock file with the new placements.
+func WithRelock() Option {
+	return func(s *TypesSplitterPlugin) {
+		s.relock = true
+	}
 }
 
 // New creates a new TypesSplitterPlugin.
-func New(cfgFilePath string) (*TypesSplitterPlugin, error) {
+func New(cfgFilePath string, opts ...Option) (*TypesSplitterPlugin, error) {
 	cfg, err := loadConfig(cfgFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	return &TypesSplitterPlugin{
+	s := &TypesSplitterPlugin{
 		cfg: cfg,
-	}, nil
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
 }
 
 func (s *TypesSplitterPlugin) init(genCfg *config.Config) {
 	s.genCfg = genCfg
 
-	// override the default resolver template config
-	s.genCfg.Resolver.FilenameTemplate = "{name}" + ResolversSuffix + ".go"
-	s.genCfg.Resolver.Layout = "follow-schema"
-
 	s.newSources = make(SourcesMap)
 	s.newSourcesDef = make(SourcesDefs)
 	s.sourcesFieldsIndex = make(map[string]map[*ast.FieldDefinition]int)
+	s.placements = make(map[string]string)
+	s.moves = nil
+	s.warnings = nil
 
 	s.initSources(genCfg)
 }
@@ -97,10 +155,16 @@ func (s *TypesSplitterPlugin) initSources(genCfg *config.Config) {
 	s.sources = make(SourcesMap)
 	s.sourcesDefs = make(SourcesDefs, len(genCfg.Sources))
 	s.sourcesFields = make(SourcesFields)
+	s.originalInputs = make(map[string]string)
+	s.sourceHeaders = make(map[string]*sourceHeader)
 
 	for _, cfgSource := range genCfg.Sources {
 		source := WrapSource(cfgSource)
 		s.sources[source.Source.Name] = source
+		s.originalInputs[source.Source.Name] = source.Input
+		if header, _ := cutHeader(source.Input); header != nil {
+			s.sourceHeaders[source.Source.Name] = header
+		}
 
 		// types
 		srcTypes, srcTypesFields := s.getSourceDefs(source, mapToList(genCfg.Schema.Types), DefTypeObject)
@@ -130,42 +194,194 @@ func (s *TypesSplitterPlugin) Name() string {
 	return PluginName
 }
 
+// Warnings returns the non-fatal issues found during the last call to MutateConfig.
+func (s *TypesSplitterPlugin) Warnings() []string {
+	return s.warnings
+}
+
+// warnf logs a non-fatal issue and keeps track of it.
+func (s *TypesSplitterPlugin) warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	s.warnings = append(s.warnings, msg)
+	log.Printf("%s: warning: %s", PluginName, msg)
+}
+
 // MutateConfig implements plugin.ConfigMutator
 func (s *TypesSplitterPlugin) MutateConfig(genCfg *config.Config) error {
-	s.init(genCfg)
+	// in dry-run mode, the split is applied to a copy of the config
+	if s.dryRun {
+		return s.dryRunSplit(genCfg)
+	}
+
+	var snapshot *sourcesSnapshot
+	if s.cfg.Mode == ModeResolvers {
+		snapshot = takeSourcesSnapshot(genCfg)
+	}
+
+	if err := s.split(genCfg); err != nil {
+		return err
+	}
+
+	// the resolver files are read before gqlgen generates them, to find the implementations of moved resolvers
+	if err := s.loadResolverFiles(); err != nil {
+		return err
+	}
 
-	// mutate and extend queries, mutations and subscriptions based on QueryConfig
-	if len(s.cfg.QueryConfig) > 0 {
-		if err := s.mutateQueryTypes(); err != nil {
-			return fmt.Errorf("failed to mutate query: %w", err)
+	// only the resolvers follow the split, the sources and the positions of what wasn't moved are kept
+	if snapshot != nil {
+		snapshot.restore(genCfg)
+		s.sourceMap.keepSources(snapshot.sources)
+	}
+
+	if err := s.splitModels(); err != nil {
+		return fmt.Errorf("failed to split models: %w", err)
+	}
+
+	if s.cfg.WriteSources {
+		if err := s.writeSources(); err != nil {
+			return fmt.Errorf("failed to write sources: %w", err)
 		}
 	}
 
+	if err := s.writeManifest(); err != nil {
+		return err
+	}
+
+	return s.writeLock()
+}
+
+// split splits the sources of the given config based on the rules.
+func (s *TypesSplitterPlugin) split(genCfg *config.Config) error {
+	s.init(genCfg)
+
+	if err := s.configureResolver(); err != nil {
+		return err
+	}
+
+	if err := s.configureExec(); err != nil {
+		return err
+	}
+
+	// validate the rules against the schema before changing anything
+	warnings, err := s.cfg.validateSchema(genCfg.Schema)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		s.warnf("%s", warning)
+	}
+
+	if err = s.loadLock(); err != nil {
+		return err
+	}
+
+	if s.templates, err = s.cfg.loadTemplates(); err != nil {
+		return err
+	}
+
+	// mutate and extend queries, mutations and subscriptions based on QueryConfig, the fields are placed
+	// even without rules so that their placement can be expected and locked
+	if err := s.mutateQueryTypes(); err != nil {
+		return fmt.Errorf("failed to mutate query: %w", err)
+	}
+
 	// mutate object types based on TypeConfig
-	if len(s.cfg.TypeConfig) > 0 {
-		if err := s.mutateObjectTypes(); err != nil {
-			return fmt.Errorf("failed to mutate object types: %w", err)
-		}
+	if err := s.mutateObjectTypes(); err != nil {
+		return fmt.Errorf("failed to mutate object types: %w", err)
 	}
 
-	for _, newSrc := range s.newSources {
+	// check that the fields and types ended up where they are expected to
+	if err := s.checkExpectations(); err != nil {
+		return err
+	}
+
+	for _, newSrcName := range sortedKeys(s.newSources) {
+		newSrc := s.newSources[newSrcName]
+		s.sortSource(newSrc)
+
 		_, err := newSrc.GenerateInput()
 		if err != nil {
 			return err
 		}
 
-		// remove extra newlines
-		newSrc.Input = removeExtraLines(newSrc.Input)
+		// remove extra newlines left by the default templates, the spacing of custom templates is kept
+		if newSrc.hasDefaultTemplate() {
+			newSrc.Input = removeExtraLines(newSrc.Input)
+		}
+
+		if s.cfg.Format == FormatCanonical {
+			if err := s.formatSource(newSrc); err != nil {
+				return err
+			}
+		}
+
+		// a source written by a previous run may already exist with the same name,
+		// in which case the new content is appended to it, and its header is replaced
+		if origSrc := s.sources[newSrc.Name]; origSrc != nil && containsSource(genCfg.Sources, origSrc.Source) {
+			origHeader, origInput := cutHeader(origSrc.Input)
+			origSrc.Input = s.header(newSrc, origHeader) + origInput
+			offset := mergeSource(origSrc, newSrc)
+			if err := relocateSource(origSrc.Source, newSrc, offset); err != nil {
+				return err
+			}
+			continue
+		}
+
+		newSrc.Input = s.header(newSrc, nil) + newSrc.Input
+
+		// point the moved fields and types to their actual position in the new source
+		if err := relocateSource(newSrc.Source, newSrc, 0); err != nil {
+			return err
+		}
 
 		// add the new sources to the config
 		genCfg.Sources = append(genCfg.Sources, newSrc.Source)
 	}
 
+	// remove the original sources that were emptied by the split, ignoring the header of sources
+	// produced by a previous split
+	for _, origSrc := range s.sources {
+		if _, input := cutHeader(origSrc.Input); strings.TrimSpace(input) == "" {
+			genCfg.Sources = removeSource(genCfg.Sources, origSrc.Source)
+		}
+	}
+
 	// reorder sources by name
 	sort.Slice(genCfg.Sources, func(i, j int) bool {
 		return genCfg.Sources[i].Name < genCfg.Sources[j].Name
 	})
 
+	if s.followsSchema() {
+		if err := checkBaseNames(genCfg.Sources, func(src *ast.Source) bool { return s.sources[src.Name] == nil }); err != nil {
+			return err
+		}
+	}
+
+	s.manifest = s.buildManifest()
+	s.sourceMap = s.buildSourceMap()
+
+	return nil
+}
+
+// sourceKindsConflict returns the error of a new source that would contain definitions of different kinds.
+func sourceKindsConflict(name, kind string, src *Source) error {
+	otherKind := typeKind
+	if typeName := src.typeName(); typeName != "" {
+		otherKind = strings.ToLower(typeName)
+	}
+
+	return fmt.Errorf("%s would contain both %s and %s definitions, use {kind} or different filename templates", name, otherKind, kind)
+}
+
+// relocateSource points the fields and types of the new source to their position in the given source, where
+// the input of the new source was added at the given offset.
+func relocateSource(src *ast.Source, newSrc *Source, offset int) error {
+	if newSrc.formatted {
+		return relocateFormatted(src, newSrc)
+	}
+
+	locate(src, newSrc.Fields, newSrc.Types, offset)
+
 	return nil
 }
 
@@ -173,8 +389,9 @@ func (s *TypesSplitterPlugin) MutateConfig(genCfg *config.Config) error {
 func (s *TypesSplitterPlugin) mutateObjectTypes() error {
 	var err error
 
-	for sourceName, definitions := range s.sourcesDefs {
-		for _, def := range definitions {
+	// sources are iterated by name so that the moves, and their side effects, don't depend on map iteration
+	for _, sourceName := range sortedKeys(s.sourcesDefs) {
+		for _, def := range s.sourcesDefs[sourceName] {
 			// we're not handing Input types...
 			// we possibly could pretty easily from here. It might be as easy as adding
 			// && def.typ != DefTypeInput to the if statement below. The content added to the source
@@ -184,13 +401,17 @@ func (s *TypesSplitterPlugin) mutateObjectTypes() error {
 				continue
 			}
 
-			prefix, ok := s.cfg.TypeConfig.FindResolverPrefix(def.Name)
-			if !ok {
+			// interfaces, unions, enums... are moved by the rules too, but only object types are placed
+			prefix, _ := s.cfg.TypeConfig.FindResolverPrefix(def.Name)
+			if def.Kind == ast.Object {
+				prefix = s.resolvePlacement(def.Name, prefix)
+			}
+			if prefix == "" {
 				continue
 			}
 
-			// the new source name is the prefix from config + the original source name
-			newSrcName := filepath.Join(filepath.Dir(sourceName), prefix+filepath.Ext(sourceName))
+			// the new source name is rendered from the filename template of types
+			newSrcName := s.cfg.splitSourceName(sourceName, typeKind, prefix)
 
 			// check if the new source conflicts with an existing source
 			if sourceName == newSrcName {
@@ -199,10 +420,15 @@ func (s *TypesSplitterPlugin) mutateObjectTypes() error {
 
 			// check if that source exists
 			newExistingSrc, ok := s.newSources[newSrcName]
+			if ok && newExistingSrc.typ != SourceObject {
+				return sourceKindsConflict(newSrcName, typeKind, newExistingSrc)
+			}
 			if !ok {
 				if newExistingSrc, err = NewSource(newSrcName, SourceObject); err != nil {
 					return err
 				}
+				newExistingSrc.Prefix = prefix
+				newExistingSrc.templates = s.templates
 				s.newSources[newSrcName] = newExistingSrc
 			}
 
@@ -214,6 +440,8 @@ func (s *TypesSplitterPlugin) mutateObjectTypes() error {
 			if err = s.moveType(s.sources[sourceName], def); err != nil {
 				return err
 			}
+
+			s.recordTypeMove(newExistingSrc, def, prefix)
 		}
 	}
 
@@ -304,19 +532,13 @@ func (s *TypesSplitterPlugin) moveType(fromSource *Source, typeToMove *Definitio
 func (s *TypesSplitterPlugin) mutateQueryTypes() error {
 	var err error
 
-	for sourceName, fields := range s.sourcesFields {
-		for _, field := range fields {
+	// sources are iterated by name so that the moves, and their side effects, don't depend on map iteration
+	for _, sourceName := range sortedKeys(s.sourcesFields) {
+		for _, field := range s.sourcesFields[sourceName] {
 			if !isQueryTypeField(field) {
 				continue
 			}
 
-			// any field not found in the query config is ignored
-			// and will kept in the root query source
-			prefix, ok := s.cfg.QueryConfig.FindResolverPrefix(field.Name)
-			if !ok {
-				continue
-			}
-
 			// origQuery is the original query, mutation or subscription that contains the field
 			var origQuery *ast.Definition
 			var sourceType SourceType
@@ -333,8 +555,16 @@ func (s *TypesSplitterPlugin) mutateQueryTypes() error {
 				sourceType = SourceSubscriptionExtended
 			}
 
-			// the new source name is the prefix from config + the original source name
-			newSrcName := filepath.Join(filepath.Dir(sourceName), prefix+"."+filepath.Base(sourceName))
+			// any field not found in the query config is ignored
+			// and will kept in the root query source
+			rulePrefix, _ := s.cfg.QueryConfig.FindResolverPrefix(field.Name)
+			prefix := s.resolvePlacement(fieldPlacementKey(origQuery, field), rulePrefix)
+			if prefix == "" {
+				continue
+			}
+
+			// the new source name is rendered from the filename template of the kind of the field
+			newSrcName := s.cfg.splitSourceName(sourceName, fieldKind(field.typ), prefix)
 
 			// check if the new source conflicts with an existing source
 			if sourceName == newSrcName {
@@ -343,10 +573,15 @@ func (s *TypesSplitterPlugin) mutateQueryTypes() error {
 
 			// check if that source exists
 			newExistingSrc, ok := s.newSources[newSrcName]
+			if ok && newExistingSrc.typ != sourceType {
+				return sourceKindsConflict(newSrcName, fieldKind(field.typ), newExistingSrc)
+			}
 			if !ok {
 				if newExistingSrc, err = NewSource(newSrcName, sourceType); err != nil {
 					return err
 				}
+				newExistingSrc.Prefix = prefix
+				newExistingSrc.templates = s.templates
 				s.newSources[newSrcName] = newExistingSrc
 			}
 
@@ -359,6 +594,8 @@ func (s *TypesSplitterPlugin) mutateQueryTypes() error {
 			if err != nil {
 				return err
 			}
+
+			s.recordFieldMove(newExistingSrc, origQuery, field, prefix)
 		}
 	}
 
@@ -457,11 +694,12 @@ func (s *TypesSplitterPlugin) moveQueryField(fromOriginalDef *ast.Definition, fi
 			}
 
 			// remove the source from the config sources
-			for i, src := range s.genCfg.Sources {
-				if src.Name == cfgSrc.Name {
-					s.genCfg.Sources = append(s.genCfg.Sources[:i], s.genCfg.Sources[i+1:]...)
-					break
-				}
+			s.genCfg.Sources = removeSource(s.genCfg.Sources, cfgSrc)
+
+			// sources only extending the type, eg. written by a previous split, don't define it
+			if fromOriginalDef.Position.Src.Name != cfgSrc.Name {
+				delete(s.sourcesFields, cfgSrc.Name)
+				return true, nil
 			}
 
 			// update the main query source to be the source of the first field of the same source type.
@@ -512,7 +750,11 @@ func (s *TypesSplitterPlugin) getSourceDefs(src *Source, srcDefs ast.DefinitionL
 		if srcDef == nil || srcDef.Position == nil {
 			continue
 		}
-		if srcDef.Position.Src != src.Source {
+
+		// Query, Mutation and Subscription fields may be defined in extensions from other sources,
+		// in which case only the fields defined in this source are returned.
+		inSource := srcDef.Position.Src == src.Source
+		if !inSource && typ == DefTypeObject {
 			continue
 		}
 
@@ -520,26 +762,30 @@ func (s *TypesSplitterPlugin) getSourceDefs(src *Source, srcDefs ast.DefinitionL
 			continue
 		}
 
-		if typ == DefTypeObject && srcDef.Kind == "INPUT_OBJECT" {
-			if srcDef.Kind == "INPUT_OBJECT" {
-				typ = DefInputObject
-			} else if srcDef.Kind == "SCALAR" {
-				typ = DefScalar
+		// the kind is checked for each definition, as the list mixes object, input and scalar types
+		defTyp := typ
+		if typ == DefTypeObject {
+			switch srcDef.Kind {
+			case ast.InputObject:
+				defTyp = DefInputObject
+			case ast.Scalar:
+				defTyp = DefScalar
 			}
 		}
 
-		def := WrapDefinition(srcDef, typ)
-		defs = append(defs, def)
-
 		for _, field := range srcDef.Fields {
-			if field == nil || field.Position == nil || typ == DefScalar {
+			if field == nil || field.Position == nil || field.Position.Src != src.Source || defTyp == DefScalar {
 				continue
 			}
 			defFields = append(defFields, WrapFieldDefinition(field, fieldTyp))
 		}
-
-		def.AddFields(defFields)
 		fields = append(fields, defFields...)
+
+		if inSource {
+			def := WrapDefinition(srcDef, defTyp)
+			def.AddFields(defFields)
+			defs = append(defs, def)
+		}
 	}
 
 	return defs, fields
@@ -592,17 +838,27 @@ func countLines(s string) int {
 	return strings.Count(s, "\n")
 }
 
+// mapToList returns the values of the map, ordered by key.
 func mapToList[T any](m map[string]T) []T {
-	l := make([]T, len(m))
-	i := 0
-	for _, v := range m {
-		l[i] = v
-		i++
+	l := make([]T, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		l = append(l, m[k])
 	}
 
 	return l
 }
 
+// sortedKeys returns the sorted keys of the map.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // debug
 var debSources []debSrcChange
 var debSourcesDetailed []debSrcChange
@@ -10,19 +10,11 @@ import (
 	"github.com/99designs/gqlgen/codegen/config"
 	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
+	"gopkg.in/yaml.v3"
 )
 
 func Test_MutateConfig(t *testing.T) {
-	sources := getTestSources(t, false)
-	schema, err := gqlparser.LoadSchema(sources...)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	cfg := &config.Config{
-		Sources: sources,
-		Schema:  schema,
-	}
+	cfg := getTestGenConfig(t)
 
 	splitter, err := New("./test_data/gqlgen_plugins.yml")
 	if err != nil {
@@ -48,6 +40,34 @@ func Test_MutateConfig(t *testing.T) {
 	}
 }
 
+// getTestGenConfig returns a gqlgen config loaded with the test input sources.
+func getTestGenConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	sources := getTestSources(t, false)
+	schema, err := gqlparser.LoadSchema(sources...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &config.Config{
+		Sources: sources,
+		Schema:  schema,
+	}
+}
+
+// getTestConfig reads the given plugin config.
+func getTestConfig(t *testing.T, cfg string) *SplitterConfig {
+	t.Helper()
+
+	splitterCfg, err := readConfig(strings.NewReader(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return splitterCfg
+}
+
 func getTestSources(t *testing.T, isExpected bool) []*ast.Source {
 	t.Helper()
 
@@ -81,3 +101,355 @@ func getTestSources(t *testing.T, isExpected bool) []*ast.Source {
 
 	return srcList
 }
+
+func Test_MutateConfig_Expect(t *testing.T) {
+	const managerRule = `
+  types:
+    - name: Manager
+      prefix: managers.users`
+
+	tests := []struct {
+		name    string
+		types   string
+		expect  string
+		wantErr string
+	}{
+		{
+			name:  "expectations are met",
+			types: managerRule,
+			expect: `
+    getUser: users
+    Mutation.createUser: users
+    node: ""
+    Manager: managers.users
+    Editor: ""`,
+		},
+		{
+			name:  "expectations are not met",
+			types: managerRule,
+			expect: `
+    getPost: users
+    Editor: editors
+    getFoo: foo`,
+			wantErr: `placements don't match the expectations:
+--- expected
++++ actual
+- Editor: editors
++ Editor: ""
+- getFoo: foo
++ getFoo: (no root field or object type named getFoo)
+- getPost: users
++ getPost: posts`,
+		},
+		{
+			name: "no type rules",
+			expect: `
+    getUser: users
+    Manager: ""
+    Editor: ""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := getTestConfig(t, `
+types_splitter:`+tt.types+`
+  queries:
+    - prefix: posts
+      matches:
+        - post
+    - prefix: users
+      matches:
+        - user
+  expect:`+tt.expect)
+
+			splitter := &TypesSplitterPlugin{cfg: cfg}
+
+			err := splitter.MutateConfig(getTestGenConfig(t))
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("expected error:\n%s\ngot:\n%v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_MutateConfig_NonObjectTypeRule(t *testing.T) {
+	cfg := getTestConfig(t, `
+types_splitter:
+  types:
+    - name: Node
+      prefix: nodes
+`)
+
+	splitter := &TypesSplitterPlugin{cfg: cfg}
+	genCfg := getTestGenConfig(t)
+	if err := splitter.MutateConfig(genCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// interfaces are moved by the type rules, but only object types are placed
+	var moved bool
+	for _, src := range genCfg.Sources {
+		if src.Name == "nodes.graphql" {
+			moved = strings.Contains(src.Input, "interface Node {")
+		}
+	}
+	if !moved {
+		t.Error("expected Node to be moved to nodes.graphql")
+	}
+	if _, ok := splitter.placements["Node"]; ok {
+		t.Error("expected Node not to be placed")
+	}
+}
+
+func Test_MutateConfig_DryRun(t *testing.T) {
+	cfg := getTestConfig(t, `
+types_splitter:
+  queries:
+    - prefix: users
+      matches:
+        - getUser
+    - prefix: posts
+      matches:
+        - ^(create|update|delete)Post$
+`)
+
+	out := &strings.Builder{}
+	splitter := &TypesSplitterPlugin{cfg: cfg}
+	WithDryRun(out)(splitter)
+
+	genCfg := getTestGenConfig(t)
+	if err := splitter.MutateConfig(genCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// the config is left untouched
+	for i, src := range getTestSources(t, false) {
+		if genCfg.Sources[i].Name != src.Name || genCfg.Sources[i].Input != src.Input {
+			t.Errorf("expected source %s to be unchanged", src.Name)
+		}
+	}
+	if genCfg.Schema.Mutation.Position.Src.Name != "mutations.graphql" {
+		t.Errorf("expected Mutation to be unchanged, got source %s", genCfg.Schema.Mutation.Position.Src.Name)
+	}
+
+	expected := `--- mutations.graphql
++++ mutations.graphql
+@@ -13,13 +13,4 @@
+ 
+     """Create a new editor with the specified name, email, age, and bio"""
+     createEditor(name: String!, email: String!, age: Int, bio: String!): Editor!
+-
+-    """Create a new post with the specified title, content, and editor ID"""
+-    createPost(title: String!, content: String!, editorId: ID!): Post!
+-
+-    """Update a post with the specified ID and fields"""
+-    updatePost(id: ID!, title: String, content: String): Post
+-
+-    """Delete a post with the specified ID"""
+-    deletePost(id: ID!): ID
+ }
+--- /dev/null
++++ posts.mutations.graphql
+@@ -0,0 +1,10 @@
++extend type Mutation {
++    """Create a new post with the specified title, content, and editor ID"""
++    createPost(title: String!, content: String!, editorId: ID!): Post!
++
++    """Update a post with the specified ID and fields"""
++    updatePost(id: ID!, title: String, content: String): Post
++
++    """Delete a post with the specified ID"""
++    deletePost(id: ID!): ID
++}
+--- queries.graphql
++++ queries.graphql
+@@ -1,7 +1,4 @@
+ type Query {
+-    """Get a user by ID"""
+-    getUser(id: ID!): User @auth
+-
+     """Get a post by ID"""
+     getPost(id: ID!): Post
+     @auth
+--- /dev/null
++++ users.queries.graphql
+@@ -0,0 +1,4 @@
++extend type Query {
++    """Get a user by ID"""
++    getUser(id: ID!): User @auth
++}
+`
+	if splitter.Diff() != expected {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", expected, splitter.Diff())
+	}
+	if out.String() != expected {
+		t.Errorf("expected diff to be written to the output, got:\n%s", out.String())
+	}
+}
+
+func Test_MutateConfig_Manifest(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := loadConfig("./test_data/gqlgen_plugins.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.dir = dir
+	cfg.Manifest = "manifest.yml"
+
+	splitter := &TypesSplitterPlugin{cfg: cfg}
+	if err = splitter.MutateConfig(getTestGenConfig(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "manifest.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &Manifest{}
+	if err = yaml.Unmarshal(b, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifest.Moves) != 9 {
+		t.Fatalf("expected 9 moves, got %d", len(manifest.Moves))
+	}
+
+	expected := ManifestMove{
+		Kind:           "query",
+		Name:           "Query.getPostsByEditor",
+		Prefix:         "posts",
+		Rule:           "queries[0]",
+		Match:          "post",
+		OriginalSource: "queries.graphql",
+		OriginalLine:   29,
+		Source:         "posts.queries.graphql",
+		Line:           6,
+	}
+	if *manifest.Moves[6] != expected {
+		t.Errorf("expected move %+v, got %+v", expected, *manifest.Moves[6])
+	}
+
+	expected = ManifestMove{
+		Kind:           "type",
+		Name:           "Manager",
+		Prefix:         "managers.users",
+		Rule:           "types[1]",
+		OriginalSource: "users.graphql",
+		OriginalLine:   51,
+		Source:         "managers.users.graphql",
+		Line:           1,
+	}
+	if *manifest.Moves[1] != expected {
+		t.Errorf("expected move %+v, got %+v", expected, *manifest.Moves[1])
+	}
+
+	if strings.Join(manifest.Created, ",") != "editors.mutations.graphql,managers.users.graphql,posts.mutations.graphql,posts.queries.graphql,users.mutations.graphql,users.queries.graphql" {
+		t.Errorf("unexpected created sources %s", manifest.Created)
+	}
+	if strings.Join(manifest.Deleted, ",") != "mutations.graphql" {
+		t.Errorf("unexpected deleted sources %s", manifest.Deleted)
+	}
+}
+
+func Test_MutateConfig_EmptyManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := getTestConfig(t, `
+types_splitter:
+  manifest: manifest.json
+  queries:
+    - prefix: comments
+      matches:
+        - comment
+`)
+	cfg.dir = dir
+
+	splitter := &TypesSplitterPlugin{cfg: cfg}
+	if err := splitter.MutateConfig(getTestGenConfig(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing moved, the lists are empty rather than null
+	expected := "{\n  \"moves\": [],\n  \"created\": [],\n  \"deleted\": []\n}\n"
+	if string(b) != expected {
+		t.Errorf("expected manifest:\n%s\ngot:\n%s", expected, b)
+	}
+}
+
+func Test_MutateConfig_Templates(t *testing.T) {
+	dir := t.TempDir()
+
+	tpl := `# Copyright (c) ACME
+# {{ .Source }}: {{ .Prefix }} from {{ range .Originals }}{{ . }} {{ end }}by {{ range .Rules }}{{ . }} {{ end }}
+extend type {{ .Type }} {
+{{ range .Fields }}{{ .Content }}
+
+
+{{ end }}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "query.tmpl"), []byte(tpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := getTestConfig(t, `
+types_splitter:
+  templates:
+    query_extended: query.tmpl
+  queries:
+    - prefix: posts
+      matches:
+        - post
+`)
+	cfg.dir = dir
+
+	splitter := &TypesSplitterPlugin{cfg: cfg}
+	genCfg := getTestGenConfig(t)
+	if err := splitter.MutateConfig(genCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var postsQueries *ast.Source
+	for _, src := range genCfg.Sources {
+		if src.Name == "posts.queries.graphql" {
+			postsQueries = src
+		}
+	}
+	if postsQueries == nil {
+		t.Fatal("posts.queries.graphql wasn't generated")
+	}
+
+	header := "# Copyright (c) ACME\n# posts.queries.graphql: posts from queries.graphql by queries[0] \nextend type Query {\n"
+	if !strings.HasPrefix(postsQueries.Input, header) {
+		t.Errorf("unexpected input:\n%s", postsQueries.Input)
+	}
+
+	// the blank lines of custom templates are kept, between fields and before the closing bracket
+	if !strings.Contains(postsQueries.Input, "    @auth\n\n\n    \"\"\"Get posts written by an editor") || !strings.HasSuffix(postsQueries.Input, "PRIVATE)\n\n\n\n}\n") {
+		t.Errorf("expected the spacing of the template to be kept, got:\n%s", postsQueries.Input)
+	}
+
+	// moved fields are located in the custom layout
+	for _, move := range splitter.Manifest().Moves {
+		if move.Source == postsQueries.Name && move.Line < 4 {
+			t.Errorf("%s wasn't relocated after the header, line %d", move.Name, move.Line)
+		}
+	}
+
+	// missing templates are reported
+	cfg.Templates.Object = "missing.tmpl"
+	if err := (&TypesSplitterPlugin{cfg: cfg}).MutateConfig(getTestGenConfig(t)); err == nil || !strings.Contains(err.Error(), "unable to read template") {
+		t.Errorf("expected a template error, got %v", err)
+	}
+}
@@ -1,6 +1,11 @@
 package types_splitter_plugin
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
 
 func Test_findClosingBracket1(t *testing.T) {
 	type args struct {
@@ -110,3 +115,45 @@ return "hello (name)
 		})
 	}
 }
+
+func Test_extractDefContent(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "Definition on the first line",
+			input: "type User {\n  id: ID!\n}\n",
+			want:  "type User {\n  id: ID!\n}",
+		},
+		{
+			name:  "Description on the first line",
+			input: "\"\"\"A user\"\"\"\ntype User {\n  id: ID!\n}\n",
+			want:  "\"\"\"A user\"\"\"\ntype User {\n  id: ID!\n}",
+		},
+		{
+			name:  "Block description on the first line",
+			input: "\"\"\"\nA user\n\"\"\"\ntype User {\n  id: ID!\n}\n",
+			want:  "\"\"\"\nA user\n\"\"\"\ntype User {\n  id: ID!\n}",
+		},
+		{
+			name:  "Definition after another one",
+			input: "scalar Time\n\n\"\"\"A user\"\"\"\ntype User {\n  id: ID!\n}\n",
+			want:  "\"\"\"A user\"\"\"\ntype User {\n  id: ID!\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.ParseSchema(&ast.Source{Name: "users.graphql", Input: tt.input})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, _, _, _ := extractDefContent(DefTypeObject, doc.Definitions.ForName("User")); got != tt.want {
+				t.Errorf("extractDefContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
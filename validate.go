@@ -0,0 +1,173 @@
+package types_splitter_plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// invalidPrefixChars are characters that are not allowed in a prefix, as they would produce
+// file names that are invalid on some systems, or that would escape the source directory.
+const invalidPrefixChars = `/\<>:"|?*`
+
+// validateSchema validates every rule of the config against the given schema.
+//
+// Prefixes that would produce invalid file names are always rejected. Rules that can't
+// have any effect (unknown types, queries matching nothing...) and filename templates
+// sharing base names between prefixes are returned as warnings, or rejected if the config
+// is strict.
+func (c *SplitterConfig) validateSchema(schema *ast.Schema) (warnings []string, err error) {
+	var errs []string
+
+	for _, queryCfg := range c.QueryConfig {
+		if err := validatePrefix(queryCfg.ResolverPrefix); err != nil {
+			errs = append(errs, fmt.Sprintf("query config %q: %s", queryCfg.ResolverPrefix, err))
+		}
+	}
+
+	for _, typeCfg := range c.TypeConfig {
+		if err := validatePrefix(typeCfg.ResolverPrefix); err != nil {
+			errs = append(errs, fmt.Sprintf("type config %q: %s", typeCfg.Name, err))
+		}
+	}
+
+	warnings = append(warnings, c.QueryConfig.deadRules(rootFieldNames(schema))...)
+	warnings = append(warnings, c.TypeConfig.deadRules(schema)...)
+	warnings = append(warnings, c.filenameTemplateWarnings()...)
+
+	if c.Strict {
+		errs = append(errs, warnings...)
+		warnings = nil
+	}
+
+	if len(errs) > 0 {
+		return warnings, fmt.Errorf("invalid config:\n  %s", strings.Join(errs, "\n  "))
+	}
+
+	return warnings, nil
+}
+
+// validatePrefix checks that the prefix can safely be used to build a file name.
+func validatePrefix(prefix string) error {
+	switch {
+	case strings.TrimSpace(prefix) == "":
+		return fmt.Errorf("empty prefix")
+	case strings.HasPrefix(prefix, ".") || strings.HasSuffix(prefix, "."):
+		return fmt.Errorf("prefix %q must not start or end with a dot", prefix)
+	case strings.Contains(prefix, ".."):
+		return fmt.Errorf("prefix %q must not contain consecutive dots", prefix)
+	case strings.ContainsAny(prefix, invalidPrefixChars):
+		return fmt.Errorf("prefix %q must not contain any of %s", prefix, invalidPrefixChars)
+	}
+
+	for _, r := range prefix {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("prefix %q must not contain spaces or control characters", prefix)
+		}
+	}
+
+	return nil
+}
+
+// deadRules returns a warning for every query config that no root field will be moved to,
+// either because its regexes match nothing, or because previous configs match first.
+func (qs QuerySplitConfigs) deadRules(fieldNames []string) []string {
+	var warnings []string
+
+	for qi, q := range qs {
+		matched := false
+		shadowed := false
+
+		for _, name := range fieldNames {
+			if !q.matchString(name) {
+				continue
+			}
+
+			if rule, ok := qs.FindRule(name); ok && rule == &qs[qi] {
+				matched = true
+				break
+			}
+			shadowed = true
+		}
+
+		switch {
+		case matched:
+		case shadowed:
+			warnings = append(warnings, fmt.Sprintf("query config %q: every matching field is already matched by a previous config", q.ResolverPrefix))
+		default:
+			warnings = append(warnings, fmt.Sprintf("query config %q: matches %q don't match any query, mutation or subscription", q.ResolverPrefix, q.Matches))
+		}
+	}
+
+	return warnings
+}
+
+// deadRules returns a warning for every type config that doesn't reference a type of the schema that can be split,
+// or that is already defined by a previous config.
+func (ts TypeSplitConfigs) deadRules(schema *ast.Schema) []string {
+	var warnings []string
+
+	seen := map[string]bool{}
+	for _, t := range ts {
+		if seen[t.Name] {
+			warnings = append(warnings, fmt.Sprintf("type config %q: type is already defined by a previous config", t.Name))
+			continue
+		}
+		seen[t.Name] = true
+
+		def := schema.Types[t.Name]
+		switch {
+		case def == nil:
+			msg := fmt.Sprintf("type config %q: unknown type", t.Name)
+			if suggestions := validator.SuggestionList(t.Name, objectTypeNames(schema)); len(suggestions) > 0 {
+				msg += fmt.Sprintf(", did you mean %q?", suggestions[0])
+			}
+			warnings = append(warnings, msg)
+		case isQueryDef(def):
+			warnings = append(warnings, fmt.Sprintf("type config %q: root types can't be split, it will be ignored", t.Name))
+		case def.Kind == ast.InputObject:
+			warnings = append(warnings, fmt.Sprintf("type config %q: input types can't be split, it will be ignored", t.Name))
+		}
+	}
+
+	return warnings
+}
+
+// rootFieldNames returns the names of all the fields of Query, Mutation and Subscription.
+func rootFieldNames(schema *ast.Schema) []string {
+	var names []string
+
+	for _, def := range []*ast.Definition{schema.Query, schema.Mutation, schema.Subscription} {
+		if def == nil {
+			continue
+		}
+
+		for _, field := range def.Fields {
+			if strings.HasPrefix(field.Name, "__") || field.Position == nil {
+				continue
+			}
+			names = append(names, field.Name)
+		}
+	}
+
+	return names
+}
+
+// objectTypeNames returns the sorted names of the object types of the schema that can be split.
+func objectTypeNames(schema *ast.Schema) []string {
+	var names []string
+
+	for name, def := range schema.Types {
+		if def.Kind != ast.Object || def.BuiltIn || isQueryDef(def) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
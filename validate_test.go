@@ -0,0 +1,89 @@
+package types_splitter_plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+)
+
+func Test_validateSchema(t *testing.T) {
+	schema, err := gqlparser.LoadSchema(getTestSources(t, false)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := readConfig(strings.NewReader(`
+types_splitter:
+  types:
+    - name: Posts
+      prefix: posts
+    - name: Node
+      prefix: nodes
+    - name: Query
+      prefix: queries
+    - name: User
+      prefix: users
+  queries:
+    - prefix: users
+      matches:
+        - user
+    - prefix: admins
+      matches:
+        - getUser
+    - prefix: comments
+      matches:
+        - comment
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warnings, err := cfg.validateSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`query config "admins": every matching field is already matched by a previous config`,
+		`query config "comments": matches ["comment"] don't match any query, mutation or subscription`,
+		`type config "Posts": unknown type, did you mean "Post"?`,
+		`type config "Query": root types can't be split, it will be ignored`,
+	}
+	if strings.Join(warnings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected warnings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(warnings, "\n"))
+	}
+
+	cfg.Strict = true
+	if _, err = cfg.validateSchema(schema); err == nil {
+		t.Error("expected strict config to be rejected")
+	}
+}
+
+func Test_validatePrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		wantErr bool
+	}{
+		{prefix: "users", wantErr: false},
+		{prefix: "managers.users", wantErr: false},
+		{prefix: "billing_v2-api", wantErr: false},
+		{prefix: "", wantErr: true},
+		{prefix: "  ", wantErr: true},
+		{prefix: ".users", wantErr: true},
+		{prefix: "users.", wantErr: true},
+		{prefix: "managers..users", wantErr: true},
+		{prefix: "../users", wantErr: true},
+		{prefix: "users/admins", wantErr: true},
+		{prefix: "users:admins", wantErr: true},
+		{prefix: "users admins", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if err := validatePrefix(tt.prefix); (err != nil) != tt.wantErr {
+				t.Errorf("validatePrefix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package types_splitter_plugin
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// writeSources writes the split sources to disk. Original sources that were changed by the split are
+// rewritten, emptied original sources are deleted, and new sources are created.
+func (s *TypesSplitterPlugin) writeSources() error {
+	names := make([]string, 0, len(s.sources))
+	for name := range s.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		src := s.sources[name]
+
+		if !containsSource(s.genCfg.Sources, src.Source) || strings.TrimSpace(src.Input) == "" {
+			if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+
+		if src.Input == s.originalInputs[name] {
+			continue
+		}
+
+		if err := writeSourceFile(name, src.Input); err != nil {
+			return err
+		}
+	}
+
+	for _, src := range s.genCfg.Sources {
+		if _, ok := s.sources[src.Name]; ok {
+			continue
+		}
+
+		if err := writeSourceFile(src.Name, src.Input); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSourceFile writes the input of a source to the file named after it.
+func writeSourceFile(name, input string) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("unable to create directory of %s: %w", name, err)
+	}
+
+	if err := os.WriteFile(name, []byte(input), 0o644); err != nil {
+		return fmt.Errorf("unable to write %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package types_splitter_plugin
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func Test_MutateConfig_WriteSources(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, src := range getTestSources(t, false) {
+		if err := os.WriteFile(filepath.Join(dir, src.Name), []byte(src.Input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	splitterCfg, err := loadConfig("./test_data/gqlgen_plugins.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	splitterCfg.WriteSources = true
+
+	// the second run reads the output of the first one, and must leave it unchanged
+	for run := 1; run <= 2; run++ {
+		splitter := &TypesSplitterPlugin{cfg: splitterCfg}
+		if err = splitter.MutateConfig(loadGenConfigFromDir(t, dir)); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := getTestSources(t, true)
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.graphql"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != len(expected) {
+			t.Fatalf("run %d: expected %d files, got %d: %s", run, len(expected), len(files), files)
+		}
+
+		for i, file := range files {
+			if filepath.Base(file) != expected[i].Name {
+				t.Errorf("run %d: expected file %s, got %s", run, expected[i].Name, filepath.Base(file))
+			}
+
+			b, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != expected[i].Input {
+				t.Errorf("run %d: expected %s input:\n%s\ngot:\n%s", run, expected[i].Name, expected[i].Input, b)
+			}
+		}
+	}
+}
+
+// loadGenConfigFromDir returns a gqlgen config loaded with the graphql files of the given directory and its
+// subdirectories.
+func loadGenConfigFromDir(t *testing.T, dir string) *config.Config {
+	t.Helper()
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && filepath.Ext(path) == ".graphql" {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sources []*ast.Source
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, &ast.Source{Name: file, Input: string(b)})
+	}
+
+	schema, err := gqlparser.LoadSchema(sources...)
+	if err != nil {
+		t.Fatal(strings.TrimSpace(err.Error()))
+	}
+
+	return &config.Config{Sources: sources, Schema: schema}
+}
+
+func Test_MutateConfig_DomainLayout(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, src := range getTestSources(t, false) {
+		if err := os.WriteFile(filepath.Join(dir, src.Name), []byte(src.Input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := getTestConfig(t, `
+types_splitter:
+  layout: domain
+  write_sources: true
+  types:
+    - name: Manager
+      prefix: managers.users
+    - name: Post
+      prefix: blog
+  queries:
+    - prefix: posts
+      matches:
+        - post
+    - prefix: users
+      matches:
+        - user|manager
+`)
+
+	var expected []string
+	for run := 1; run <= 2; run++ {
+		genCfg := loadGenConfigFromDir(t, dir)
+		if err := (&TypesSplitterPlugin{cfg: cfg}).MutateConfig(genCfg); err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, src := range genCfg.Sources {
+			name, err := filepath.Rel(dir, src.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, filepath.ToSlash(name))
+		}
+
+		if run == 1 {
+			expected = names
+			if strings.Join(names, ",") != "blog/blog.graphql,directives.graphql,enums.graphql,interfaces.graphql,managers/users/managers.users.graphql,mutations.graphql,posts.graphql,posts/posts.mutations.graphql,posts/posts.queries.graphql,queries.graphql,users.graphql,users/users.mutations.graphql,users/users.queries.graphql" {
+				t.Fatalf("unexpected sources %s", names)
+			}
+		}
+
+		// the second run reads the nested sources written by the first one, and leaves them in place
+		if strings.Join(names, ",") != strings.Join(expected, ",") {
+			t.Errorf("run %d: expected sources %s, got %s", run, expected, names)
+		}
+	}
+
+	// User would move to users/users.graphql, next to users.graphql where Editor stays, and both would have
+	// their resolvers in users.resolvers.go
+	cfg = getTestConfig(t, `
+types_splitter:
+  layout: domain
+  types:
+    - name: User
+      prefix: users
+`)
+
+	genCfg := getTestGenConfig(t)
+	genCfg.Resolver = config.ResolverConfig{Layout: config.LayoutFollowSchema, DirName: "graph"}
+
+	err := (&TypesSplitterPlugin{cfg: cfg}).MutateConfig(genCfg)
+	if err == nil || !strings.Contains(err.Error(), "sources must have different base names, as gqlgen names resolver files after them: users.graphql, users/users.graphql") {
+		t.Errorf("expected a base name error, got %v", err)
+	}
+}